@@ -0,0 +1,369 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxAPIJobs is how many finished API jobs are kept for status queries
+const maxAPIJobs = 50
+
+// apiJobSlotPrefix names the output slot of an API job
+const apiJobSlotPrefix = "api:"
+
+// API job status values
+const (
+	jobQueued    = "queued"
+	jobRunning   = "running"
+	jobCompleted = "completed"
+	jobFailed    = "failed"
+)
+
+// apiJob is a conversion started through the REST API
+type apiJob struct {
+	ID         string            `json:"id"`
+	Status     string            `json:"status"` // "queued", "running", "completed" or "failed"
+	Files      []string          `json:"files"`
+	OutputURL  string            `json:"outputUrl,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Report     *ConversionReport `json:"report,omitempty"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	FinishedAt *time.Time        `json:"finishedAt,omitempty"`
+	outputID   string            // Registry ID of the job's copy of the PDF
+}
+
+// apiJobStore keeps recent API jobs
+type apiJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]*apiJob
+}
+
+// newAPIJobStore creates an empty job store
+func newAPIJobStore() *apiJobStore {
+	return &apiJobStore{jobs: make(map[string]*apiJob)}
+}
+
+// add stores a job and drops the oldest finished jobs beyond maxAPIJobs
+func (s *apiJobStore) add(job *apiJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs[job.ID] = job
+	if len(s.jobs) <= maxAPIJobs {
+		return
+	}
+
+	var finished []*apiJob
+	for _, j := range s.jobs {
+		if j.FinishedAt != nil {
+			finished = append(finished, j)
+		}
+	}
+	sort.Slice(finished, func(i, k int) bool { return finished[i].CreatedAt.Before(finished[k].CreatedAt) })
+	for i := 0; i < len(s.jobs)-maxAPIJobs && i < len(finished); i++ {
+		delete(s.jobs, finished[i].ID)
+	}
+}
+
+// get returns a snapshot of a job
+func (s *apiJobStore) get(id string) (apiJob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, exists := s.jobs[id]
+	if !exists {
+		return apiJob{}, false
+	}
+	return *job, true
+}
+
+// update changes a job under the store lock
+func (s *apiJobStore) update(id string, fn func(job *apiJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job, exists := s.jobs[id]; exists {
+		fn(job)
+	}
+}
+
+// apiConversionRequest is the body of POST /api/v1/conversions
+type apiConversionRequest struct {
+	Files           []string            `json:"files"`
+	SheetSelections map[string][]string `json:"sheetSelections"`
+	PageSelections  map[string]string   `json:"pageSelections"`
+	Options         *PostProcessOptions `json:"options"` // Omit to use the active recipe options
+}
+
+// apiSessionRequest is the body of PUT /api/v1/session
+type apiSessionRequest struct {
+	Directory       string              `json:"directory"`
+	SelectedFiles   []string            `json:"selectedFiles"`
+	ExpandedFolders []string            `json:"expandedFolders"`
+	CurrentFile     string              `json:"currentFile"`
+	SheetSelections map[string][]string `json:"sheetSelections"`
+}
+
+// registerAPIRoutes adds the versioned REST API to the local server
+func (a *App) registerAPIRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/conversions", a.handleCreateConversion)
+	mux.HandleFunc("GET /api/v1/conversions/{id}", a.handleGetConversion)
+	mux.HandleFunc("GET /api/v1/conversions/{id}/output", a.handleGetConversionOutput)
+	mux.HandleFunc("GET /api/v1/tree", a.handleGetTree)
+	mux.HandleFunc("GET /api/v1/session", a.handleGetSession)
+	mux.HandleFunc("PUT /api/v1/session", a.handlePutSession)
+	mux.HandleFunc("GET /api/v1/events", a.handleEvents)
+}
+
+// handleCreateConversion starts a conversion job
+func (a *App) handleCreateConversion(w http.ResponseWriter, r *http.Request) {
+	var req apiConversionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if len(req.Files) == 0 {
+		writeAPIError(w, http.StatusBadRequest, "no files given")
+		return
+	}
+	if err := a.resolveConversionFiles(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts := a.currentBundleOptions(req.Files, req.SheetSelections)
+	if opts.SheetSelections == nil {
+		opts.SheetSelections = make(map[string][]string)
+	}
+	for filePath, pages := range req.PageSelections {
+		opts.PageSelections[filePath] = pages
+	}
+	if req.Options != nil {
+		opts.PostProcess = *req.Options
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	job := &apiJob{
+		ID:        id,
+		Status:    jobQueued,
+		Files:     req.Files,
+		CreatedAt: time.Now(),
+	}
+	a.apiJobs.add(job)
+
+	go a.runAPIJob(id, opts)
+
+	snapshot, _ := a.apiJobs.get(id)
+	writeJSON(w, http.StatusAccepted, snapshot)
+}
+
+// resolveConversionFiles resolves the requested paths against the working folder, as the
+// conversion cache and the file monitor key inputs by absolute path, and rejects files that
+// cannot be converted
+func (a *App) resolveConversionFiles(req *apiConversionRequest) error {
+	for i, filePath := range req.Files {
+		absPath := resolveRecipePath(a.initialDir, filePath)
+		info, err := os.Stat(absPath)
+		if err != nil || info.IsDir() {
+			return fmt.Errorf("file not found: %s", filePath)
+		}
+		if !isOfficeFile(strings.ToLower(filepath.Ext(absPath))) || isOfficeTempFile(filepath.Base(absPath)) {
+			return fmt.Errorf("unsupported file type: %s", filePath)
+		}
+		req.Files[i] = absPath
+	}
+
+	// Selections are keyed by the paths as given
+	sheetSelections := make(map[string][]string, len(req.SheetSelections))
+	for filePath, sheets := range req.SheetSelections {
+		sheetSelections[resolveRecipePath(a.initialDir, filePath)] = sheets
+	}
+	req.SheetSelections = sheetSelections
+
+	pageSelections := make(map[string]string, len(req.PageSelections))
+	for filePath, pages := range req.PageSelections {
+		pageSelections[resolveRecipePath(a.initialDir, filePath)] = pages
+	}
+	req.PageSelections = pageSelections
+	return nil
+}
+
+// runAPIJob runs a conversion job through the same path as the Wails bindings
+func (a *App) runAPIJob(id string, opts BundleOptions) {
+	a.apiJobs.update(id, func(job *apiJob) { job.Status = jobRunning })
+
+	// The job keeps its PDF under its own slot, taken before another conversion replaces the preview
+	a.conversionMu.Lock()
+	_, result, err := a.convertBundleLocked(opts)
+	var outputID string
+	if result != nil && result.outputID != "" {
+		outputID, _ = a.outputs.Share(result.outputID, apiJobSlotPrefix+id)
+	}
+	a.conversionMu.Unlock()
+
+	a.apiJobs.update(id, func(job *apiJob) {
+		now := time.Now()
+		job.FinishedAt = &now
+		if result != nil {
+			job.Report = result.Report
+		}
+		job.outputID = outputID
+		if err != nil {
+			job.Status = jobFailed
+			job.Error = err.Error()
+			return
+		}
+		job.Status = jobCompleted
+		job.OutputURL = "/api/v1/conversions/" + id + "/output"
+	})
+}
+
+// handleGetConversion returns the status of a job
+func (a *App) handleGetConversion(w http.ResponseWriter, r *http.Request) {
+	job, exists := a.apiJobs.get(r.PathValue("id"))
+	if !exists {
+		writeAPIError(w, http.StatusNotFound, "conversion not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// handleGetConversionOutput returns the PDF produced by a job
+func (a *App) handleGetConversionOutput(w http.ResponseWriter, r *http.Request) {
+	job, exists := a.apiJobs.get(r.PathValue("id"))
+	if !exists {
+		writeAPIError(w, http.StatusNotFound, "conversion not found")
+		return
+	}
+	if job.Status != jobCompleted {
+		writeAPIError(w, http.StatusConflict, fmt.Sprintf("conversion is %s", job.Status))
+		return
+	}
+	entry, exists := a.outputs.Lookup(job.outputID)
+	if !exists {
+		writeAPIError(w, http.StatusGone, "conversion output has expired")
+		return
+	}
+	servePDF(w, r, entry.path, entry.sha256)
+}
+
+// handleGetTree returns the directory tree of ?dir=, or of the working directory
+func (a *App) handleGetTree(w http.ResponseWriter, r *http.Request) {
+	dir := r.URL.Query().Get("dir")
+	if dir == "" {
+		dir = a.initialDir
+	}
+
+	tree, err := a.GetDirectoryTree(dir)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if tree == nil {
+		tree = []FileInfo{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"directory": dir,
+		"tree":      tree,
+	})
+}
+
+// handleGetSession returns the saved session of ?dir=, or of the working directory
+func (a *App) handleGetSession(w http.ResponseWriter, r *http.Request) {
+	dir := r.URL.Query().Get("dir")
+	if dir == "" {
+		dir = a.initialDir
+	}
+
+	session, err := a.LoadDirectorySessionCache(dir)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if session == nil {
+		writeAPIError(w, http.StatusNotFound, "no session for directory")
+		return
+	}
+	writeJSON(w, http.StatusOK, session)
+}
+
+// handlePutSession replaces the saved session of a directory and tells the GUI to reload it
+func (a *App) handlePutSession(w http.ResponseWriter, r *http.Request) {
+	var req apiSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if req.Directory == "" {
+		req.Directory = a.initialDir
+	}
+
+	if err := a.SaveDirectorySessionCache(req.Directory, req.SelectedFiles, req.ExpandedFolders, req.CurrentFile, req.SheetSelections); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	a.emit(eventSessionChanged, req.Directory)
+
+	session, err := a.LoadDirectorySessionCache(req.Directory)
+	if err != nil || session == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, session)
+}
+
+// serverInfo is written to serverInfoPath so local tools can find the API
+type serverInfo struct {
+	BaseURL string `json:"baseUrl"`
+	Port    int    `json:"port"`
+	Token   string `json:"token"`
+	PID     int    `json:"pid"`
+}
+
+// serverInfoPath returns where the running instance publishes its API address and token
+func serverInfoPath() string {
+	return filepath.Join(defaultCacheDir(), "server.json")
+}
+
+// writeServerInfo publishes the API address and token, readable by the current user only
+func (a *App) writeServerInfo() error {
+	data, err := json.MarshalIndent(serverInfo{
+		BaseURL: fmt.Sprintf("http://127.0.0.1:%d/api/v1", a.httpPort),
+		Port:    a.httpPort,
+		Token:   a.httpToken,
+		PID:     os.Getpid(),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal server info: %v", err)
+	}
+
+	if err := writeFileAtomic(serverInfoPath(), data, 0600); err != nil {
+		return fmt.Errorf("failed to write server info: %v", err)
+	}
+	return nil
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeAPIError writes a JSON error response
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCreateConversionRejectsBadFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"report.xlsx", "notes.txt", "~$report.xlsx"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "no files", body: `{"files": []}`, want: "no files given"},
+		{name: "missing file", body: `{"files": [` + jsonString(filepath.Join(dir, "gone.xlsx")) + `]}`, want: "file not found"},
+		{name: "directory", body: `{"files": [` + jsonString(dir) + `]}`, want: "file not found"},
+		{name: "unsupported type", body: `{"files": [` + jsonString(filepath.Join(dir, "notes.txt")) + `]}`, want: "unsupported file type"},
+		{name: "Office lock file", body: `{"files": [` + jsonString(filepath.Join(dir, "~$report.xlsx")) + `]}`, want: "unsupported file type"},
+		{
+			name: "one bad file rejects the request",
+			body: `{"files": [` + jsonString(filepath.Join(dir, "report.xlsx")) + `, ` + jsonString(filepath.Join(dir, "gone.docx")) + `]}`,
+			want: "file not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{apiJobs: newAPIJobStore()}
+			rec := httptest.NewRecorder()
+			app.handleCreateConversion(rec, httptest.NewRequest(http.MethodPost, "/api/v1/conversions", strings.NewReader(tt.body)))
+
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), tt.want) {
+				t.Fatalf("got %d %s, want 400 %q", rec.Code, rec.Body.String(), tt.want)
+			}
+			if len(app.apiJobs.jobs) != 0 {
+				t.Fatalf("rejected request queued %d jobs", len(app.apiJobs.jobs))
+			}
+		})
+	}
+}
+
+func TestResolveConversionFilesUsesWorkingFolder(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	absPath := filepath.Join(dir, "data", "Report.XLSX")
+	if err := os.WriteFile(absPath, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	app := &App{initialDir: dir}
+	req := apiConversionRequest{
+		Files:           []string{"data/Report.XLSX"},
+		SheetSelections: map[string][]string{"data/Report.XLSX": {"Sheet1"}},
+		PageSelections:  map[string]string{absPath: "1-2"},
+	}
+	if err := app.resolveConversionFiles(&req); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{absPath}; !reflect.DeepEqual(req.Files, want) {
+		t.Errorf("files %q, want %q", req.Files, want)
+	}
+	if want := map[string][]string{absPath: {"Sheet1"}}; !reflect.DeepEqual(req.SheetSelections, want) {
+		t.Errorf("sheet selections %v, want %v", req.SheetSelections, want)
+	}
+	if want := map[string]string{absPath: "1-2"}; !reflect.DeepEqual(req.PageSelections, want) {
+		t.Errorf("page selections %v, want %v", req.PageSelections, want)
+	}
+
+	// The process working directory is not the app's working folder under Wails
+	other := &App{initialDir: t.TempDir()}
+	if err := other.resolveConversionFiles(&apiConversionRequest{Files: []string{"data/Report.XLSX"}}); err == nil {
+		t.Fatal("relative path resolved outside the working folder")
+	}
+}
+
+// jsonString quotes a path for a JSON request body
+func jsonString(s string) string {
+	return `"` + strings.ReplaceAll(s, `\`, `\\`) + `"`
+}
@@ -5,24 +5,34 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // NewApp creates a new App application struct
 func NewApp(initialDir string) *App {
+	state := newStateStore(configDir())
+
+	// Move state left in the temp directory by older releases
+	if err := migrateLegacyState(state); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
 	// Create cache directory
-	cacheDir := filepath.Join(os.TempDir(), "pdf-preview-go-cache")
+	cacheDir := defaultCacheDir()
 	os.MkdirAll(cacheDir, 0755)
 
 	app := &App{
 		converter:           NewOfficeConverter(cacheDir),
+		state:               state,
+		settings:            loadSettings(state),
 		initialDir:          initialDir,
 		httpPort:            0, // Will be set when server starts
-		watchedDir:          "",
+		outputs:             newOutputRegistry(outputTTL, filepath.Join(cacheDir, outputSnapshotsDir)),
+		apiJobs:             newAPIJobStore(),
+		events:              newEventBus(),
 		lastConvertedFiles:  []string{},
 		lastConvertedSheets: make(map[string][]string),
-		autoUpdateEnabled:   true,
-		fileModTimes:        make(map[string]time.Time),
 		currentPdfPath:      "",
 		savedPdfPath:        "",
 		hasUnsavedChanges:   false,
@@ -36,41 +46,59 @@ func NewApp(initialDir string) *App {
 func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
 
-	// Start HTTP server for serving PDF files
-	a.startHTTPServer()
+	// Forward bus events to the Wails frontend
+	a.events.Listen(func(event AppEvent) {
+		runtime.EventsEmit(a.ctx, event.Type, event.Data)
+	})
 
-	// Initialize file watcher
+	// Initialize file watcher before API requests can start conversions
 	a.initFileWatcher()
 
-	// Clean up old cache files (older than 30 days)
+	// Start HTTP server for serving PDF files
+	if err := a.startHTTPServer(); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	// Push working directory changes to the file tree
+	a.watchTree(a.initialDir)
+
+	// Output options are kept per workspace
+	a.restoreWorkspaceOptions()
+
+	// Clean up old cache files after the retention periods of the settings
 	go func() {
-		if err := a.CleanupSheetSelectionsCache(30 * 24 * time.Hour); err != nil {
+		settings := a.currentSettings()
+		if err := a.CleanupSheetSelectionsCache(retention(settings.SheetSelectionDays)); err != nil {
 			fmt.Printf("Warning: failed to cleanup sheet selection cache: %v\n", err)
 		}
 
-		// Cleanup session cache (older than 3 months)
-		if err := a.CleanupDirectorySessionCache(90 * 24 * time.Hour); err != nil {
+		// Cleanup session cache
+		if err := a.CleanupDirectorySessionCache(retention(settings.SessionDays)); err != nil {
 			fmt.Printf("Warning: failed to cleanup session cache: %v\n", err)
 		}
 
 		// Also cleanup PDF cache
-		if err := a.converter.CleanupCache(30 * 24 * time.Hour); err != nil {
+		if err := a.converter.CleanupCache(retention(settings.PDFCacheDays)); err != nil {
 			fmt.Printf("Warning: failed to cleanup PDF cache: %v\n", err)
 		}
+		if err := cleanupOutputSnapshots(filepath.Join(defaultCacheDir(), outputSnapshotsDir), retention(settings.PDFCacheDays)); err != nil {
+			fmt.Printf("Warning: failed to cleanup published PDFs: %v\n", err)
+		}
 	}()
 }
 
 // Shutdown is called when the app is closing
 func (a *App) Shutdown(ctx context.Context) {
-	if a.pollingTicker != nil {
-		a.pollingTicker.Stop()
-	}
-	if a.watcher != nil {
-		a.watcher.Close()
+	if a.monitor != nil {
+		a.monitor.Stop()
 	}
+	a.stopTreeWatcher()
 	if a.httpServer != nil {
 		a.httpServer.Close()
+		os.Remove(serverInfoPath())
 	}
+	a.StopSharing()
+	a.outputs.Close()
 	// Note: OfficeConverter doesn't have a Close method
 	// COM objects are automatically cleaned up
 }
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// appDirName names the per-user config and cache directories of the app
+const appDirName = "pdf-preview-go"
+
+// stateSchemaVersion is the format version of the JSON state files.
+// Version 1 files were plain JSON; version 2 wraps the data in a checksummed envelope.
+// Files without a version were written to the temp directory by older releases.
+const stateSchemaVersion = 2
+
+// migrationMarker is created in the config directory once older state has been migrated.
+// It holds the schema version the files were migrated to.
+const migrationMarker = ".migrated"
+
+// configDir returns the directory for settings, sessions and directory history.
+// It is under os.UserConfigDir, which honors XDG_CONFIG_HOME on Linux.
+func configDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return legacyStateDir()
+	}
+	return filepath.Join(dir, appDirName)
+}
+
+// defaultCacheDir returns the directory used for converted PDFs.
+// It is under os.UserCacheDir, which honors XDG_CACHE_HOME on Linux.
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return legacyStateDir()
+	}
+	return filepath.Join(dir, appDirName)
+}
+
+// legacyStateDir is where older releases kept both state and cached PDFs
+func legacyStateDir() string {
+	return filepath.Join(os.TempDir(), "pdf-preview-go-cache")
+}
+
+// isStateFileName reports whether name is a session, sheet selection or history file
+func isStateFileName(name string) bool {
+	if name == directoryHistoryName {
+		return true
+	}
+	return strings.HasSuffix(name, ".json") &&
+		(strings.HasPrefix(name, "session_") || strings.HasPrefix(name, "sheet_selections_"))
+}
+
+// migrateLegacyState moves state files and cached PDFs from the temp directory
+// to the config and cache directories and upgrades state files to the current schema, once.
+// Files that already exist in the new place are kept.
+func migrateLegacyState(store *stateStore) error {
+	legacyDir := legacyStateDir()
+	stateDir := configDir()
+	cacheDir := defaultCacheDir()
+
+	markerPath := filepath.Join(stateDir, migrationMarker)
+	if migratedVersion(markerPath) >= stateSchemaVersion {
+		return nil
+	}
+
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %v", err)
+	}
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %v", err)
+	}
+
+	var failed []string
+
+	// Upgrade files written by releases that stored plain JSON in the config directory
+	entries, err := os.ReadDir(stateDir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", stateDir, err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !isStateFileName(name) {
+			continue
+		}
+		if err := migrateStateFile(store, filepath.Join(stateDir, name), name); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+
+	if legacyDir != stateDir {
+		entries, err := os.ReadDir(legacyDir)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %v", legacyDir, err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			name := entry.Name()
+			src := filepath.Join(legacyDir, name)
+
+			switch {
+			case isStateFileName(name):
+				if err := migrateStateFile(store, src, name); err != nil {
+					failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+					continue
+				}
+				os.Remove(src)
+
+			case strings.HasSuffix(name, ".pdf") || strings.HasSuffix(name, ".pdf.json"):
+				// Cached PDFs only save reconversion; a move across volumes is not worth copying
+				dst := filepath.Join(cacheDir, name)
+				if _, err := os.Stat(dst); os.IsNotExist(err) {
+					os.Rename(src, dst)
+				}
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to migrate %s", strings.Join(failed, ", "))
+	}
+	return writeFileAtomic(markerPath, []byte(strconv.Itoa(stateSchemaVersion)+"\n"), 0644)
+}
+
+// migratedVersion returns the schema version recorded in the migration marker.
+// Markers of version 1 held the migration time instead.
+func migratedVersion(markerPath string) int {
+	data, err := os.ReadFile(markerPath)
+	if err != nil {
+		return 0
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 1
+	}
+	return version
+}
+
+// migrateStateFile saves the plain JSON state file src as name in the store,
+// keeping its modification time. Files the store can already read are left alone.
+func migrateStateFile(store *stateStore, src, name string) error {
+	if _, err := readStateFile(store.path(name)); err == nil {
+		return nil
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	var state interface{}
+	switch {
+	case name == directoryHistoryName:
+		history, err := parseLegacyDirectoryHistory(data)
+		if err != nil {
+			return err
+		}
+		state = history
+	case strings.HasPrefix(name, "session_"):
+		var cache DirectorySessionCache
+		if err := json.Unmarshal(data, &cache); err != nil {
+			return err
+		}
+		state = cache
+	default:
+		var cache SheetSelectionCache
+		if err := json.Unmarshal(data, &cache); err != nil {
+			return err
+		}
+		state = cache
+	}
+
+	if err := store.Save(name, state); err != nil {
+		return err
+	}
+	return os.Chtimes(store.path(name), info.ModTime(), info.ModTime())
+}
+
+// parseLegacyDirectoryHistory reads a plain JSON directory history.
+// Releases before the config directory stored a bare list; version 1 wrapped it in an object.
+func parseLegacyDirectoryHistory(data []byte) ([]DirectoryHistory, error) {
+	var history []DirectoryHistory
+	if err := json.Unmarshal(data, &history); err == nil {
+		return history, nil
+	}
+
+	var file struct {
+		Directories []DirectoryHistory `json:"directories"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	return file.Directories, nil
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultBatchWorkers limits how many Office instances a batch runs at once
+const defaultBatchWorkers = 2
+
+// Batch item status values
+const (
+	batchConverted = "converted"
+	batchSkipped   = "skipped" // Output PDF is already up to date
+	batchFailed    = "failed"
+)
+
+// BatchOptions controls a batch conversion of a directory tree
+type BatchOptions struct {
+	RootDir   string   `json:"rootDir"`   // Directory tree to convert
+	OutputDir string   `json:"outputDir"` // Root of a mirrored output tree; empty writes each PDF next to its source
+	Include   []string `json:"include"`   // Glob patterns to include; empty includes every Office file
+	Exclude   []string `json:"exclude"`   // Glob patterns to exclude; matching directories are not descended into
+	Workers   int      `json:"workers"`   // Number of parallel conversions
+	Force     bool     `json:"force"`     // Convert even if the PDF is up to date
+}
+
+// BatchItem is the outcome for one source file
+type BatchItem struct {
+	Source     string `json:"source"`
+	Output     string `json:"output"`
+	Status     string `json:"status"` // "converted", "skipped" or "failed"
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// BatchSummary summarizes a batch conversion
+type BatchSummary struct {
+	Converted  int         `json:"converted"`
+	Skipped    int         `json:"skipped"`
+	Failed     int         `json:"failed"`
+	DurationMs int64       `json:"durationMs"`
+	Items      []BatchItem `json:"items"`
+}
+
+// runBatch converts every matching Office file below opts.RootDir to its own PDF.
+// It converts through a scratch cache of its own, so it never rewrites a cached PDF
+// that the preview, auto-update or the API is writing or serving.
+func runBatch(opts BatchOptions, progress ProgressFunc) (*BatchSummary, error) {
+	start := time.Now()
+	if progress == nil {
+		progress = func(ConversionStatus) {}
+	}
+
+	rootDir, err := filepath.Abs(opts.RootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve directory: %v", err)
+	}
+	if info, err := os.Stat(rootDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", rootDir)
+	}
+
+	outputDir := ""
+	if opts.OutputDir != "" {
+		if outputDir, err = filepath.Abs(opts.OutputDir); err != nil {
+			return nil, fmt.Errorf("failed to resolve output directory: %v", err)
+		}
+	}
+
+	sources, err := collectBatchSources(rootDir, outputDir, opts.Include, opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	items := planBatchItems(rootDir, outputDir, sources)
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = defaultBatchWorkers
+	}
+
+	scratchDir, err := os.MkdirTemp("", "pdf-preview-batch-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scratch directory: %v", err)
+	}
+	defer os.RemoveAll(scratchDir)
+	converter := NewOfficeConverter(scratchDir)
+
+	var mu sync.Mutex
+	done := 0
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				convertBatchItem(converter, &items[i], opts.Force)
+
+				mu.Lock()
+				done++
+				progress(ConversionStatus{
+					Status:      "running",
+					CurrentFile: filepath.Base(items[i].Source),
+					Progress:    done * 100 / len(items),
+				})
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range items {
+		if items[i].Status == "" {
+			jobs <- i
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	summary := &BatchSummary{Items: items}
+	for _, item := range items {
+		switch item.Status {
+		case batchConverted:
+			summary.Converted++
+		case batchSkipped:
+			summary.Skipped++
+		default:
+			summary.Failed++
+		}
+	}
+	summary.DurationMs = time.Since(start).Milliseconds()
+
+	return summary, nil
+}
+
+// convertBatchItem converts one source unless its PDF is up to date
+func convertBatchItem(converter *OfficeConverter, item *BatchItem, force bool) {
+	start := time.Now()
+	defer func() {
+		item.DurationMs = time.Since(start).Milliseconds()
+	}()
+
+	srcInfo, err := os.Stat(item.Source)
+	if err != nil {
+		item.Status = batchFailed
+		item.Error = err.Error()
+		return
+	}
+
+	if !force {
+		if outInfo, err := os.Stat(item.Output); err == nil && !outInfo.ModTime().Before(srcInfo.ModTime()) {
+			item.Status = batchSkipped
+			return
+		}
+	}
+
+	result := converter.Convert(item.Source, nil, force)
+	if result.Error != nil {
+		item.Status = batchFailed
+		item.Error = result.Error.Error()
+		return
+	}
+	// The scratch copy is only needed until it is copied to the output
+	defer os.Remove(result.OutputPath + ".json")
+	defer os.Remove(result.OutputPath)
+
+	if err := os.MkdirAll(filepath.Dir(item.Output), 0755); err != nil {
+		item.Status = batchFailed
+		item.Error = fmt.Sprintf("failed to create output directory: %v", err)
+		return
+	}
+
+	// Copy through a temporary file so a failed copy never leaves a truncated PDF behind
+	tmpPath := item.Output + ".tmp"
+	if err := copyFile(result.OutputPath, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		item.Status = batchFailed
+		item.Error = fmt.Sprintf("failed to write PDF: %v", err)
+		return
+	}
+	if err := os.Rename(tmpPath, item.Output); err != nil {
+		os.Remove(tmpPath)
+		item.Status = batchFailed
+		item.Error = fmt.Sprintf("failed to write PDF: %v", err)
+		return
+	}
+
+	item.Status = batchConverted
+}
+
+// planBatchItems assigns each source its output PDF up front, so that name collisions
+// (a.xlsx and a.docx) are reported instead of overwritten. The first source in order keeps the output.
+func planBatchItems(rootDir, outputDir string, sources []string) []BatchItem {
+	items := make([]BatchItem, len(sources))
+	owners := make(map[string]string)
+	for i, source := range sources {
+		items[i] = BatchItem{Source: source, Output: batchOutputPath(rootDir, outputDir, source)}
+		key := strings.ToLower(items[i].Output)
+		if owner, exists := owners[key]; exists {
+			items[i].Status = batchFailed
+			items[i].Error = fmt.Sprintf("output collides with %s", filepath.Base(owner))
+			continue
+		}
+		owners[key] = source
+	}
+	return items
+}
+
+// collectBatchSources walks rootDir and returns the Office files to convert, sorted by path
+func collectBatchSources(rootDir, outputDir string, include, exclude []string) ([]string, error) {
+	var sources []string
+	err := filepath.WalkDir(rootDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries
+		}
+
+		relPath, relErr := filepath.Rel(rootDir, filePath)
+		if relErr != nil || relPath == "." {
+			return nil
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if entry.IsDir() {
+			// Never descend into the mirrored output tree or excluded directories
+			if (outputDir != "" && filePath == outputDir) || matchAnyGlob(exclude, relPath) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		name := entry.Name()
+		ext := strings.ToLower(filepath.Ext(name))
+		if !isOfficeFile(ext) || ext == ".pdf" || isOfficeTempFile(name) {
+			return nil
+		}
+		if len(include) > 0 && !matchAnyGlob(include, relPath) {
+			return nil
+		}
+		if matchAnyGlob(exclude, relPath) {
+			return nil
+		}
+
+		sources = append(sources, filePath)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %v", err)
+	}
+
+	sort.Strings(sources)
+	return sources, nil
+}
+
+// batchOutputPath returns the PDF path for a source, either next to it or in the mirrored tree
+func batchOutputPath(rootDir, outputDir, source string) string {
+	pdfName := strings.TrimSuffix(filepath.Base(source), filepath.Ext(source)) + ".pdf"
+	if outputDir == "" {
+		return filepath.Join(filepath.Dir(source), pdfName)
+	}
+
+	relDir, err := filepath.Rel(rootDir, filepath.Dir(source))
+	if err != nil {
+		relDir = ""
+	}
+	return filepath.Join(outputDir, relDir, pdfName)
+}
+
+// matchAnyGlob reports whether a slash-separated relative path matches any of the patterns
+func matchAnyGlob(patterns []string, relPath string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(filepath.ToSlash(pattern), relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a relative path against a glob pattern.
+// Patterns without a slash match the file name only; "**" matches any number of directories.
+func matchGlob(pattern, relPath string) bool {
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(relPath))
+		return matched
+	}
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(relPath, "/"))
+}
+
+// matchGlobSegments matches pattern segments against path segments
+func matchGlobSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchGlobSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+		return false
+	}
+	return matchGlobSegments(pattern[1:], segments[1:])
+}
+
+// BatchConvert converts every Office file below a directory to its own PDF
+func (a *App) BatchConvert(opts BatchOptions) (*BatchSummary, error) {
+	if opts.RootDir == "" {
+		opts.RootDir = a.initialDir
+	}
+
+	summary, err := runBatch(opts, func(status ConversionStatus) {
+		a.emit(eventBatchProgress, status)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.emit(eventBatchCompleted, summary)
+	return summary, nil
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		relPath string
+		want    bool
+	}{
+		// Without a slash only the name is matched, at any depth
+		{pattern: "*.xlsx", relPath: "report.xlsx", want: true},
+		{pattern: "*.xlsx", relPath: "a/b/report.xlsx", want: true},
+		{pattern: "*.xlsx", relPath: "report.docx", want: false},
+		{pattern: "old", relPath: "archive/old", want: true},
+
+		// With a slash every segment must match
+		{pattern: "reports/*.xlsx", relPath: "reports/q1.xlsx", want: true},
+		{pattern: "reports/*.xlsx", relPath: "reports/2024/q1.xlsx", want: false},
+		{pattern: "reports/*.xlsx", relPath: "other/q1.xlsx", want: false},
+		{pattern: "reports/[ab].xlsx", relPath: "reports/c.xlsx", want: false},
+
+		// "**" matches zero or more directories
+		{pattern: "reports/**/*.xlsx", relPath: "reports/q1.xlsx", want: true},
+		{pattern: "reports/**/*.xlsx", relPath: "reports/2024/q1/summary.xlsx", want: true},
+		{pattern: "reports/**/*.xlsx", relPath: "reports/2024/q1/summary.docx", want: false},
+		{pattern: "reports/**/*.xlsx", relPath: "drafts/reports/q1.xlsx", want: false},
+		{pattern: "**/tmp", relPath: "tmp", want: true},
+		{pattern: "**/tmp", relPath: "a/b/tmp", want: true},
+		{pattern: "**/tmp", relPath: "a/tmp/b", want: false},
+		{pattern: "old/**", relPath: "old", want: true},
+		{pattern: "old/**", relPath: "old/2023/a.xlsx", want: true},
+		{pattern: "a/**/b/**/c.xlsx", relPath: "a/x/b/y/z/c.xlsx", want: true},
+		{pattern: "a/**/b/**/c.xlsx", relPath: "a/x/y/z/c.xlsx", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.relPath); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.relPath, got, tt.want)
+		}
+	}
+}
+
+func TestCollectBatchSources(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{
+		"a.xlsx",
+		"notes.txt",
+		"~$a.xlsx",
+		"a.pdf",
+		"old/b.xlsx",
+		"archive/2023/c.docx",
+		"reports/d.xlsx",
+		"reports/drafts/e.docx",
+		"out/a.pdf",
+		"out/f.xlsx",
+	} {
+		filePath := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		outputDir string
+		include   []string
+		exclude   []string
+		want      []string
+	}{
+		{
+			name: "every Office file",
+			want: []string{"a.xlsx", "archive/2023/c.docx", "old/b.xlsx", "out/f.xlsx", "reports/d.xlsx", "reports/drafts/e.docx"},
+		},
+		{
+			// "old" names no file, so b.xlsx is left out only because its directory is not walked
+			name:    "excluded directories are pruned",
+			exclude: []string{"old", "archive/**", "drafts"},
+			want:    []string{"a.xlsx", "out/f.xlsx", "reports/d.xlsx"},
+		},
+		{
+			name:      "output tree is not walked",
+			outputDir: filepath.Join(root, "out"),
+			exclude:   []string{"*.docx"},
+			want:      []string{"a.xlsx", "old/b.xlsx", "reports/d.xlsx"},
+		},
+		{
+			name:    "include and exclude",
+			include: []string{"reports/**/*"},
+			exclude: []string{"e.docx"},
+			want:    []string{"reports/d.xlsx"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources, err := collectBatchSources(root, tt.outputDir, tt.include, tt.exclude)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, source := range sources {
+				relPath, err := filepath.Rel(root, source)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, filepath.ToSlash(relPath))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("sources %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanBatchItems(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "src")
+	out := filepath.Join(t.TempDir(), "out")
+	source := func(name string) string { return filepath.Join(root, filepath.FromSlash(name)) }
+
+	type plan struct {
+		output string // Relative to the output directory, or to root without one
+		err    string
+	}
+	tests := []struct {
+		name      string
+		outputDir string
+		sources   []string
+		want      []plan
+	}{
+		{
+			name:      "two sources map to the same PDF under the output directory",
+			outputDir: out,
+			sources:   []string{source("sales/report.docx"), source("sales/report.xlsx")},
+			want:      []plan{{output: "sales/report.pdf"}, {output: "sales/report.pdf", err: "output collides with report.docx"}},
+		},
+		{
+			name:      "collisions ignore case",
+			outputDir: out,
+			sources:   []string{source("Budget.xlsx"), source("budget.xls")},
+			want:      []plan{{output: "Budget.pdf"}, {output: "budget.pdf", err: "output collides with Budget.xlsx"}},
+		},
+		{
+			name:      "same name in different directories",
+			outputDir: out,
+			sources:   []string{source("q1/report.xlsx"), source("q2/report.xlsx")},
+			want:      []plan{{output: "q1/report.pdf"}, {output: "q2/report.pdf"}},
+		},
+		{
+			name:    "next to the sources",
+			sources: []string{source("report.docx"), source("report.xlsx"), source("sub/report.xlsx")},
+			want:    []plan{{output: "report.pdf"}, {output: "report.pdf", err: "output collides with report.docx"}, {output: "sub/report.pdf"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := root
+			if tt.outputDir != "" {
+				base = tt.outputDir
+			}
+
+			items := planBatchItems(root, tt.outputDir, tt.sources)
+			if len(items) != len(tt.want) {
+				t.Fatalf("%d items, want %d", len(items), len(tt.want))
+			}
+			for i, item := range items {
+				want := tt.want[i]
+				if item.Source != tt.sources[i] {
+					t.Errorf("item %d source %q, want %q", i, item.Source, tt.sources[i])
+				}
+				if wantOutput := filepath.Join(base, filepath.FromSlash(want.output)); item.Output != wantOutput {
+					t.Errorf("item %d output %q, want %q", i, item.Output, wantOutput)
+				}
+				wantStatus := ""
+				if want.err != "" {
+					wantStatus = batchFailed
+				}
+				if item.Status != wantStatus || item.Error != want.err {
+					t.Errorf("item %d status %q error %q, want %q %q", i, item.Status, item.Error, wantStatus, want.err)
+				}
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"crypto/md5"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -19,12 +18,6 @@ func (a *App) SaveSheetSelections(filePath string, sheetSelections map[string][]
 
 	// Create cache directory hash
 	dirHash := a.createDirectoryHash(filePath)
-	cacheFilePath := filepath.Join(os.TempDir(), "pdf-preview-go-cache", fmt.Sprintf("sheet_selections_%s.json", dirHash))
-
-	// Ensure cache directory exists
-	if err := os.MkdirAll(filepath.Dir(cacheFilePath), 0755); err != nil {
-		return fmt.Errorf("failed to create cache directory: %v", err)
-	}
 
 	// Create file hashes for validation
 	fileHashes := make(map[string]string)
@@ -43,22 +36,22 @@ func (a *App) SaveSheetSelections(filePath string, sheetSelections map[string][]
 		LastUpdated:   time.Now(),
 		Selections:    sheetSelections,
 		FileHashes:    fileHashes,
-		ExpiryTime:    time.Now().AddDate(0, 1, 0), // Expire after 1 month
-	}
-
-	// Write to JSON file
-	data, err := json.MarshalIndent(cache, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal cache data: %v", err)
+		ExpiryTime:    time.Now().Add(retention(a.currentSettings().SheetSelectionDays)),
+		SheetRefs:     sheetRefsByFile(sheetSelections),
 	}
 
-	if err := os.WriteFile(cacheFilePath, data, 0644); err != nil {
+	if err := a.state.Save(sheetSelectionsStateName(dirHash), cache); err != nil {
 		return fmt.Errorf("failed to write cache file: %v", err)
 	}
 
 	return nil
 }
 
+// sheetSelectionsStateName is the state file of the sheet selections of a directory
+func sheetSelectionsStateName(dirHash string) string {
+	return fmt.Sprintf("sheet_selections_%s.json", dirHash)
+}
+
 // LoadSheetSelections loads sheet selections from cache
 func (a *App) LoadSheetSelections(dirPath string) (map[string][]string, error) {
 	if dirPath == "" {
@@ -67,61 +60,56 @@ func (a *App) LoadSheetSelections(dirPath string) (map[string][]string, error) {
 
 	// Create cache directory hash
 	dirHash := a.createDirectoryHash(dirPath)
-	cacheFilePath := filepath.Join(os.TempDir(), "pdf-preview-go-cache", fmt.Sprintf("sheet_selections_%s.json", dirHash))
-
-	// Check if cache file exists
-	if _, err := os.Stat(cacheFilePath); os.IsNotExist(err) {
-		return make(map[string][]string), nil // No cache, return empty map
-	}
-
-	// Read cache file
-	data, err := os.ReadFile(cacheFilePath)
-	if err != nil {
-		return make(map[string][]string), nil // Can't read cache, return empty map
-	}
 
-	// Parse JSON
 	var cache SheetSelectionCache
-	if err := json.Unmarshal(data, &cache); err != nil {
-		return make(map[string][]string), nil // Invalid cache, return empty map
+	if exists, err := a.state.Load(sheetSelectionsStateName(dirHash), &cache); err != nil || !exists {
+		return make(map[string][]string), nil // No cache or invalid cache, return empty map
 	}
 
 	// Check if cache is expired
 	if time.Now().After(cache.ExpiryTime) {
 		// Remove expired cache file
-		os.Remove(cacheFilePath)
+		a.state.Remove(sheetSelectionsStateName(dirHash))
 		return make(map[string][]string), nil
 	}
 
-	// Validate file hashes to ensure files haven't changed
+	validSelections, _ := a.validSheetSelections(cache.Selections, cache.SheetRefs, cache.FileHashes)
+	return validSelections, nil
+}
+
+// validSheetSelections drops the selections of files that no longer exist and reapplies
+// the others to workbooks changed since they were saved. Unchanged files are not read.
+func (a *App) validSheetSelections(selections map[string][]string, refs map[string][]SheetRef, fileHashes map[string]string) (map[string][]string, map[string][]SheetSelectionIssue) {
 	validSelections := make(map[string][]string)
-	for path, sheets := range cache.Selections {
+	issues := make(map[string][]SheetSelectionIssue)
+	for path, sheets := range selections {
 		// Check if file still exists
 		if _, err := os.Stat(path); os.IsNotExist(err) {
 			continue // File no longer exists, skip
 		}
 
 		// Check if file hash matches
-		if expectedHash, exists := cache.FileHashes[path]; exists {
-			currentHash, err := a.calculateFileHash(path)
-			if err == nil && currentHash == expectedHash {
+		if expectedHash, exists := fileHashes[path]; exists {
+			if currentHash, err := a.calculateFileHash(path); err == nil && currentHash == expectedHash {
 				// File hasn't changed, keep the selections
 				validSelections[path] = sheets
+				continue
 			}
 		}
-	}
 
-	return validSelections, nil
+		names, fileIssues := reconcileSheets(path, namedSheetRefs(refs, path, sheets))
+		validSelections[path] = names
+		if len(fileIssues) > 0 {
+			issues[path] = fileIssues
+		}
+	}
+	return validSelections, issues
 }
 
 // CleanupSheetSelectionsCache removes old sheet selection cache files
 func (a *App) CleanupSheetSelectionsCache(maxAge time.Duration) error {
-	cacheDir := filepath.Join(os.TempDir(), "pdf-preview-go-cache")
-	entries, err := os.ReadDir(cacheDir)
+	entries, err := a.state.List("sheet_selections_")
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil // Cache directory doesn't exist yet
-		}
 		return err
 	}
 
@@ -129,40 +117,21 @@ func (a *App) CleanupSheetSelectionsCache(maxAge time.Duration) error {
 	cleaned := 0
 
 	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		// Only process sheet selection cache files
-		if !strings.HasPrefix(entry.Name(), "sheet_selections_") || !strings.HasSuffix(entry.Name(), ".json") {
-			continue
-		}
-
-		filePath := filepath.Join(cacheDir, entry.Name())
-
-		// Check file modification time
-		info, err := entry.Info()
-		if err != nil {
-			continue
-		}
-
 		// Also check cache content for expiry
-		shouldRemove := info.ModTime().Before(cutoff)
+		shouldRemove := entry.ModTime.Before(cutoff)
 
 		if !shouldRemove {
 			// Check cache file content for expiry
-			if data, err := os.ReadFile(filePath); err == nil {
-				var cache SheetSelectionCache
-				if json.Unmarshal(data, &cache) == nil {
-					if time.Now().After(cache.ExpiryTime) {
-						shouldRemove = true
-					}
+			var cache SheetSelectionCache
+			if exists, err := a.state.Load(entry.Name, &cache); err == nil && exists {
+				if time.Now().After(cache.ExpiryTime) {
+					shouldRemove = true
 				}
 			}
 		}
 
 		if shouldRemove {
-			if err := os.Remove(filePath); err == nil {
+			if err := a.state.Remove(entry.Name); err == nil {
 				cleaned++
 			}
 		}
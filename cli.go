@@ -0,0 +1,246 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Exit codes returned by the headless commands
+const (
+	exitOK             = 0 // Every input was converted
+	exitFailure        = 1 // Nothing could be produced
+	exitPartialFailure = 2 // Output was written but some inputs failed
+	exitUsage          = 3 // Invalid command line
+)
+
+// runCommand runs a headless subcommand if args start with one.
+// It reports whether a subcommand was handled and the process exit code.
+func runCommand(args []string) (int, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+
+	switch args[0] {
+	case "convert":
+		return runConvertCommand(args[1:], os.Stderr), true
+	case "watch":
+		return runWatchCommand(args[1:], os.Stderr), true
+	case "batch":
+		return runBatchCommand(args[1:], os.Stderr), true
+	}
+	return 0, false
+}
+
+// runConvertCommand implements `pdf-preview-go convert -o out.pdf a.xlsx:Sheet1,Sheet3 b.docx c.pdf`
+// and `pdf-preview-go convert -recipe bundle.recipe.json [-o out.pdf]`
+func runConvertCommand(args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	outputPath := fs.String("o", "", "output PDF path (overrides the recipe output)")
+	recipePath := fs.String("recipe", "", "recipe file describing the bundle")
+	reportPath := fs.String("report", "", "write a JSON conversion report to this path")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: pdf-preview-go convert [-report <report.json>] -o <output.pdf> <file>[:Sheet1,Sheet2] ...")
+		fmt.Fprintln(stderr, "       pdf-preview-go convert [-report <report.json>] -recipe <recipe.json> [-o <output.pdf>]")
+		fs.PrintDefaults()
+	}
+
+	inputs, err := parseFlags(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+
+	var opts BundleOptions
+	if *recipePath != "" {
+		if len(inputs) > 0 {
+			fs.Usage()
+			return exitUsage
+		}
+		recipe, err := LoadRecipe(*recipePath)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitUsage
+		}
+		opts = recipe.BundleOptions(*recipePath)
+	} else {
+		if len(inputs) == 0 {
+			fs.Usage()
+			return exitUsage
+		}
+		opts = parseInputSpecs(inputs)
+	}
+
+	if *outputPath != "" {
+		opts.OutputPath = *outputPath
+	}
+	if opts.OutputPath == "" {
+		fmt.Fprintln(stderr, "Error: no output path given")
+		fs.Usage()
+		return exitUsage
+	}
+
+	absOutput, err := filepath.Abs(opts.OutputPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error resolving output path: %v\n", err)
+		return exitUsage
+	}
+	opts.OutputPath = absOutput
+	if err := os.MkdirAll(filepath.Dir(absOutput), 0755); err != nil {
+		fmt.Fprintf(stderr, "Error creating output directory: %v\n", err)
+		return exitFailure
+	}
+
+	return convertBundle(opts, *reportPath, stderr)
+}
+
+// runBatchCommand implements `pdf-preview-go batch [-out dir] [-include glob] [-exclude glob] [-j n] [-force] <dir>`
+func runBatchCommand(args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var opts BatchOptions
+	fs.StringVar(&opts.OutputDir, "out", "", "mirror PDFs into this directory instead of next to each file")
+	fs.Var((*stringList)(&opts.Include), "include", "glob of files to convert (repeatable)")
+	fs.Var((*stringList)(&opts.Exclude), "exclude", "glob of files or directories to skip (repeatable)")
+	fs.IntVar(&opts.Workers, "j", defaultBatchWorkers, "number of parallel conversions")
+	fs.BoolVar(&opts.Force, "force", false, "convert even if the PDF is up to date")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: pdf-preview-go batch [options] <directory>")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	opts.RootDir = positional[0]
+
+	summary, err := runBatch(opts, func(status ConversionStatus) {
+		fmt.Fprintf(stderr, "[%3d%%] %s\n", status.Progress, status.CurrentFile)
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	for _, item := range summary.Items {
+		if item.Status == batchFailed {
+			fmt.Fprintf(stderr, "Error: %s: %s\n", item.Source, item.Error)
+		}
+	}
+	fmt.Fprintf(stderr, "%d converted, %d up to date, %d failed (%s)\n",
+		summary.Converted, summary.Skipped, summary.Failed, (time.Duration(summary.DurationMs) * time.Millisecond).String())
+
+	switch {
+	case summary.Failed == 0:
+		return exitOK
+	case summary.Converted+summary.Skipped > 0:
+		return exitPartialFailure
+	default:
+		return exitFailure
+	}
+}
+
+// stringList is a repeatable string flag
+type stringList []string
+
+// String implements flag.Value
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set implements flag.Value
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// parseFlags parses flags that may appear before or after the positional arguments
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// parseInputSpecs builds bundle options from "file[:Sheet1,Sheet2]" arguments
+func parseInputSpecs(args []string) BundleOptions {
+	opts := BundleOptions{SheetSelections: make(map[string][]string)}
+	for _, arg := range args {
+		filePath, sheets := parseInputSpec(arg)
+		if absPath, err := filepath.Abs(filePath); err == nil {
+			filePath = absPath
+		}
+		opts.Files = append(opts.Files, filePath)
+		if len(sheets) > 0 {
+			opts.SheetSelections[filePath] = sheets
+		}
+	}
+	return opts
+}
+
+// convertBundle builds a bundle with progress on stderr and maps the outcome to an exit code
+func convertBundle(opts BundleOptions, reportPath string, stderr io.Writer) int {
+	start := time.Now()
+	converter := NewOfficeConverter(defaultCacheDir())
+
+	result, err := buildBundle(converter, opts, func(status ConversionStatus) {
+		fmt.Fprintf(stderr, "[%3d%%] %s\n", status.Progress, status.CurrentFile)
+	})
+	for _, msg := range result.Errors {
+		fmt.Fprintf(stderr, "Error: %s\n", msg)
+	}
+	if reportPath != "" {
+		if err := WriteConversionReport(reportPath, result.Report); err != nil {
+			fmt.Fprintf(stderr, "Warning: %v\n", err)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Conversion failed: %v\n", err)
+		return exitFailure
+	}
+
+	fmt.Fprintf(stderr, "[100%%] Wrote %s (%d/%d files, %s)\n",
+		result.OutputPath, len(result.Converted), len(opts.Files), time.Since(start).Round(time.Millisecond))
+	if result.Partial() {
+		return exitPartialFailure
+	}
+	return exitOK
+}
+
+// parseInputSpec splits "file.xlsx:Sheet1,Sheet2" into the file path and its sheet names.
+// A colon that belongs to a Windows drive letter or an existing file name is not treated as a separator.
+func parseInputSpec(spec string) (string, []string) {
+	if _, err := os.Stat(spec); err == nil {
+		return spec, nil
+	}
+
+	idx := strings.LastIndex(spec, ":")
+	if idx <= 1 || strings.ContainsAny(spec[idx+1:], `/\`) {
+		return spec, nil
+	}
+
+	var sheets []string
+	for _, name := range strings.Split(spec[idx+1:], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			sheets = append(sheets, name)
+		}
+	}
+	return spec[:idx], sheets
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long the watch command waits for file events to settle before rebuilding
+const watchDebounce = 500 * time.Millisecond
+
+// runWatchCommand implements `pdf-preview-go watch recipe.json -o out.pdf`.
+// It rebuilds the output whenever the recipe or one of its inputs changes.
+func runWatchCommand(args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	outputPath := fs.String("o", "", "output PDF path (overrides the recipe output)")
+	reportPath := fs.String("report", "", "write a JSON conversion report to this path after every build")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: pdf-preview-go watch <recipe.json> [-o <output.pdf>] [-report <report.json>]")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	recipePath, err := filepath.Abs(positional[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error resolving recipe path: %v\n", err)
+		return exitUsage
+	}
+
+	// Validate the recipe once up front so typos fail fast
+	if _, err := loadWatchBundle(recipePath, *outputPath); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error creating file watcher: %v\n", err)
+		return exitFailure
+	}
+	defer watcher.Close()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	converter := NewOfficeConverter(defaultCacheDir())
+	watchedDirs := newDirWatchSet(watcher)
+	targets := map[string]bool{filepath.Clean(recipePath): true}
+	changed := make(map[string]bool) // Inputs to reconvert on the next build
+
+	// Build once immediately, then after every burst of changes
+	rebuild := time.NewTimer(0)
+	defer rebuild.Stop()
+
+	for {
+		select {
+		case <-interrupt:
+			fmt.Fprintln(stderr, "Stopped watching")
+			return exitOK
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return exitFailure
+			}
+			if eventPath := filepath.Clean(event.Name); targets[eventPath] {
+				changed[eventPath] = true
+				rebuild.Reset(watchDebounce)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return exitFailure
+			}
+			fmt.Fprintf(stderr, "[%s] File watcher error: %v\n", time.Now().Format("15:04:05"), err)
+
+		case <-rebuild.C:
+			opts, err := loadWatchBundle(recipePath, *outputPath)
+			if err != nil {
+				fmt.Fprintf(stderr, "[%s] Error: %v\n", time.Now().Format("15:04:05"), err)
+				continue
+			}
+
+			// Follow recipe edits that add or remove inputs
+			targets = map[string]bool{filepath.Clean(recipePath): true}
+			for _, filePath := range opts.Files {
+				targets[filepath.Clean(filePath)] = true
+			}
+			watchFiles := make([]string, 0, len(targets))
+			for target := range targets {
+				watchFiles = append(watchFiles, target)
+			}
+			for _, err := range watchedDirs.SetFiles(watchFiles) {
+				fmt.Fprintf(stderr, "Warning: %v\n", err)
+			}
+
+			for filePath := range changed {
+				opts.Changed = append(opts.Changed, filePath)
+			}
+			changed = make(map[string]bool)
+
+			runWatchBuild(converter, opts, *reportPath, stderr)
+		}
+	}
+}
+
+// loadWatchBundle loads the recipe and applies the output override
+func loadWatchBundle(recipePath, outputOverride string) (BundleOptions, error) {
+	recipe, err := LoadRecipe(recipePath)
+	if err != nil {
+		return BundleOptions{}, err
+	}
+
+	opts := recipe.BundleOptions(recipePath)
+	if outputOverride != "" {
+		opts.OutputPath = outputOverride
+	}
+	if opts.OutputPath == "" {
+		return BundleOptions{}, fmt.Errorf("no output path given")
+	}
+
+	absOutput, err := filepath.Abs(opts.OutputPath)
+	if err != nil {
+		return BundleOptions{}, fmt.Errorf("failed to resolve output path: %v", err)
+	}
+	opts.OutputPath = absOutput
+
+	return opts, nil
+}
+
+// runWatchBuild rebuilds the output atomically and prints the timing
+func runWatchBuild(converter *OfficeConverter, opts BundleOptions, reportPath string, stderr io.Writer) {
+	start := time.Now()
+	if err := os.MkdirAll(filepath.Dir(opts.OutputPath), 0755); err != nil {
+		fmt.Fprintf(stderr, "[%s] Error creating output directory: %v\n", start.Format("15:04:05"), err)
+		return
+	}
+
+	result, err := buildBundleAtomically(converter, opts, nil)
+	if result != nil {
+		for _, msg := range result.Errors {
+			fmt.Fprintf(stderr, "[%s] Error: %s\n", start.Format("15:04:05"), msg)
+		}
+		if reportPath != "" {
+			if err := WriteConversionReport(reportPath, result.Report); err != nil {
+				fmt.Fprintf(stderr, "[%s] Warning: %v\n", start.Format("15:04:05"), err)
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "[%s] Build failed after %s: %v\n", start.Format("15:04:05"), time.Since(start).Round(time.Millisecond), err)
+		return
+	}
+
+	fmt.Fprintf(stderr, "[%s] Rebuilt %s in %s (%d/%d files)\n",
+		start.Format("15:04:05"), result.OutputPath, time.Since(start).Round(time.Millisecond), len(result.Converted), len(opts.Files))
+}
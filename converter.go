@@ -2,10 +2,13 @@ package main
 
 import (
 	"crypto/md5"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 	"time"
 
@@ -27,14 +30,44 @@ func NewOfficeConverter(cacheDir string) *OfficeConverter {
 	}
 }
 
+// Conversion backends reported in ConvertResult
+const (
+	backendCopy  = "copy"  // PDF inputs are copied as-is
+	backendExcel = "excel" // Excel COM automation
+	backendWord  = "word"  // Word COM automation
+)
+
 // ConvertResult contains the result of a conversion operation
 type ConvertResult struct {
 	OutputPath string
 	Error      error
+	Backend    string           // Backend that produced the PDF
+	CacheHit   bool             // Whether an up-to-date cached PDF was reused
+	SheetPages []SheetPageCount // Printed pages per exported sheet, in output order (Excel only)
+	Warnings   []string         // Non-fatal problems
+}
+
+// SheetPageCount is the number of printed pages of one Excel sheet
+type SheetPageCount struct {
+	Sheet string `json:"sheet"`
+	Pages int    `json:"pages"`
+}
+
+// conversionMeta is stored next to a cached PDF so cache hits can still report details
+type conversionMeta struct {
+	Backend      string                     `json:"backend"`
+	SheetPages   []SheetPageCount           `json:"sheetPages,omitempty"`
+	Dependencies map[string]dependencyStamp `json:"dependencies,omitempty"` // Linked files when the PDF was made
 }
 
 // ConvertToPDF converts an Office file to PDF using Office applications
 func (c *OfficeConverter) ConvertToPDF(srcPath string, selectedSheets map[string][]string, force bool) (string, error) {
+	result := c.Convert(srcPath, selectedSheets, force)
+	return result.OutputPath, result.Error
+}
+
+// Convert converts an Office file to PDF and reports how the PDF was produced
+func (c *OfficeConverter) Convert(srcPath string, selectedSheets map[string][]string, force bool) ConvertResult {
 	// Generate cache file name based on file hash and sheet selection
 	hashInput := srcPath
 	if sheets, exists := selectedSheets[srcPath]; exists && len(sheets) > 0 {
@@ -43,77 +76,136 @@ func (c *OfficeConverter) ConvertToPDF(srcPath string, selectedSheets map[string
 	hash := md5.Sum([]byte(hashInput))
 	outputFileName := fmt.Sprintf("%x.pdf", hash)
 	outputPath := filepath.Join(c.cacheDir, outputFileName)
+	metaPath := outputPath + ".json"
 
 	// Create cache directory if it doesn't exist
 	if err := os.MkdirAll(c.cacheDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create cache directory: %v", err)
+		return ConvertResult{Error: fmt.Errorf("failed to create cache directory: %v", err)}
 	}
 
 	// Check if source file exists
 	srcInfo, err := os.Stat(srcPath)
 	if err != nil {
-		return "", fmt.Errorf("source file not found: %v", err)
+		return ConvertResult{Error: fmt.Errorf("source file not found: %v", err)}
 	}
 
+	ext := strings.ToLower(filepath.Ext(srcPath))
+
+	// Linked workbooks and included files are rendered into the PDF, so their state is part of the cache key.
+	// They are recorded before converting so that a change during the conversion is caught next time.
+	dependencies := dependencyStamps(srcPath)
+
 	// Check if output already exists and is up to date (unless force is true)
 	if !force {
 		if outputInfo, err := os.Stat(outputPath); err == nil {
-			if srcInfo.ModTime().Equal(outputInfo.ModTime()) {
-				return outputPath, nil // File is up to date
+			meta, metaErr := readConversionMeta(metaPath)
+			if srcInfo.ModTime().Equal(outputInfo.ModTime()) && dependenciesCurrent(meta, dependencies) {
+				result := ConvertResult{OutputPath: outputPath, CacheHit: true, Backend: backendForExt(ext)}
+				if metaErr == nil {
+					result.Backend = meta.Backend
+					result.SheetPages = meta.SheetPages
+				}
+				return result // File is up to date
 			}
 		}
 	}
 
-	ext := strings.ToLower(filepath.Ext(srcPath))
-
 	// Handle PDF files (just copy)
 	if ext == ".pdf" {
 		if err := copyFile(srcPath, outputPath); err != nil {
-			return "", err
+			return ConvertResult{Error: err}
 		}
-		return outputPath, nil
+		return ConvertResult{OutputPath: outputPath, Backend: backendCopy}
 	}
 
+	// COM is initialized per OS thread, so keep this goroutine on one thread until it is uninitialized.
+	// Parallel batch workers and the preview each run their own Office instance.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
 	// Initialize COM
 	if err := ole.CoInitializeEx(0, ole.COINIT_MULTITHREADED); err != nil {
-		return "", fmt.Errorf("failed to initialize COM: %v", err)
+		return ConvertResult{Error: fmt.Errorf("failed to initialize COM: %v", err)}
 	}
 	defer ole.CoUninitialize()
 
+	result := ConvertResult{OutputPath: outputPath, Backend: backendForExt(ext)}
+
 	// Convert based on file type
 	switch ext {
 	case ".xlsx", ".xls", ".xlsm":
-		err = c.convertExcelToPDF(srcPath, outputPath, selectedSheets[srcPath])
+		result.SheetPages, err = c.convertExcelToPDF(srcPath, outputPath, selectedSheets[srcPath])
+		if err == nil && result.SheetPages == nil {
+			result.Warnings = append(result.Warnings, "sheet page counts are not available")
+		}
 	case ".docx", ".doc":
 		err = c.convertWordToPDF(srcPath, outputPath)
 	default:
-		return "", fmt.Errorf("unsupported file type: %s", ext)
+		return ConvertResult{Error: fmt.Errorf("unsupported file type: %s", ext)}
 	}
 
 	if err != nil {
-		return "", err
+		return ConvertResult{Error: err, Backend: result.Backend}
 	}
 
 	// Set the same modification time as source file
 	if err := os.Chtimes(outputPath, srcInfo.ModTime(), srcInfo.ModTime()); err != nil {
-		// Log warning but don't fail
+		result.Warnings = append(result.Warnings, fmt.Sprintf("failed to set cache time: %v", err))
 	}
 
-	return outputPath, nil
+	writeConversionMeta(metaPath, conversionMeta{Backend: result.Backend, SheetPages: result.SheetPages, Dependencies: dependencies})
+
+	return result
+}
+
+// backendForExt returns the conversion backend used for a file extension
+func backendForExt(ext string) string {
+	switch ext {
+	case ".pdf":
+		return backendCopy
+	case ".xlsx", ".xls", ".xlsm":
+		return backendExcel
+	case ".docx", ".doc":
+		return backendWord
+	}
+	return ""
+}
+
+// readConversionMeta reads the metadata stored next to a cached PDF
+func readConversionMeta(metaPath string) (*conversionMeta, error) {
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var meta conversionMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// writeConversionMeta stores metadata next to a cached PDF; failures only cost report detail
+func writeConversionMeta(metaPath string, meta conversionMeta) {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return
+	}
+	writeFileAtomic(metaPath, data, 0644)
 }
 
 // convertExcelToPDF converts Excel file to PDF using Excel application
-func (c *OfficeConverter) convertExcelToPDF(srcPath, outputPath string, selectedSheets []string) error {
+func (c *OfficeConverter) convertExcelToPDF(srcPath, outputPath string, selectedSheets []string) ([]SheetPageCount, error) {
 	// Create Excel application
 	unknown, err := oleutil.CreateObject("Excel.Application")
 	if err != nil {
-		return fmt.Errorf("failed to create Excel application: %v", err)
+		return nil, fmt.Errorf("failed to create Excel application: %v", err)
 	}
 	defer unknown.Release()
 
 	excel, err := unknown.QueryInterface(ole.IID_IDispatch)
 	if err != nil {
-		return fmt.Errorf("failed to get Excel IDispatch: %v", err)
+		return nil, fmt.Errorf("failed to get Excel IDispatch: %v", err)
 	}
 	defer excel.Release()
 
@@ -125,10 +217,10 @@ func (c *OfficeConverter) convertExcelToPDF(srcPath, outputPath string, selected
 	workbooks := oleutil.MustGetProperty(excel, "Workbooks").ToIDispatch()
 	defer workbooks.Release()
 
-	// Open workbook
-	workbook, err := oleutil.CallMethod(workbooks, "Open", srcPath, false, true)
+	// Open workbook, reading the current values of linked workbooks
+	workbook, err := oleutil.CallMethod(workbooks, "Open", srcPath, 3, true) // 3 = update external references
 	if err != nil {
-		return fmt.Errorf("failed to open Excel file: %v", err)
+		return nil, fmt.Errorf("failed to open Excel file: %v", err)
 	}
 	defer func() {
 		oleutil.PutProperty(workbook.ToIDispatch(), "Saved", true)
@@ -137,6 +229,7 @@ func (c *OfficeConverter) convertExcelToPDF(srcPath, outputPath string, selected
 	}()
 
 	wb := workbook.ToIDispatch()
+	var sheetPages []SheetPageCount
 
 	// Handle sheet selection
 	if len(selectedSheets) > 0 {
@@ -156,6 +249,20 @@ func (c *OfficeConverter) convertExcelToPDF(srcPath, outputPath string, selected
 			sheet.Release()
 		}
 
+		// Sheets deleted since they were selected cannot be shown or activated
+		var existing, missing []string
+		for _, selectedName := range selectedSheets {
+			if slices.Contains(allSheetNames, selectedName) {
+				existing = append(existing, selectedName)
+			} else {
+				missing = append(missing, selectedName)
+			}
+		}
+		if len(existing) == 0 {
+			return nil, fmt.Errorf("selected sheets not found: %s", strings.Join(missing, ", "))
+		}
+		selectedSheets = existing
+
 		// Hide non-selected sheets
 		for _, sheetName := range allSheetNames {
 			isSelected := false
@@ -187,22 +294,85 @@ func (c *OfficeConverter) convertExcelToPDF(srcPath, outputPath string, selected
 			firstSheet.Release()
 		}
 
+		sheetPages = visibleSheetPageCounts(worksheets)
+
 		fmt.Printf("Exporting workbook with selected sheets only\n")
 		// Export entire workbook (now only visible sheets will be exported)
 		_, err = oleutil.CallMethod(wb, "ExportAsFixedFormat", 0, outputPath, 0)
 		if err != nil {
-			return fmt.Errorf("failed to export Excel to PDF: %v", err)
+			return nil, fmt.Errorf("failed to export Excel to PDF: %v", err)
 		}
 	} else {
+		worksheets := oleutil.MustGetProperty(wb, "Worksheets").ToIDispatch()
+		sheetPages = visibleSheetPageCounts(worksheets)
+		worksheets.Release()
+
 		fmt.Printf("No specific sheets selected, exporting entire workbook\n")
 		// Export entire workbook
 		_, err = oleutil.CallMethod(wb, "ExportAsFixedFormat", 0, outputPath, 0)
 		if err != nil {
-			return fmt.Errorf("failed to export Excel to PDF: %v", err)
+			return nil, fmt.Errorf("failed to export Excel to PDF: %v", err)
 		}
 	}
 
-	return nil
+	return sheetPages, nil
+}
+
+// visibleSheetPageCounts returns the printed page count of each visible sheet in workbook order.
+// It returns nil if Excel cannot report page counts.
+func visibleSheetPageCounts(worksheets *ole.IDispatch) []SheetPageCount {
+	countVar, err := oleutil.GetProperty(worksheets, "Count")
+	if err != nil {
+		return nil
+	}
+
+	var counts []SheetPageCount
+	for i := 1; i <= int(countVar.Val); i++ {
+		sheetVar, err := oleutil.GetProperty(worksheets, "Item", i)
+		if err != nil {
+			return nil
+		}
+		sheet := sheetVar.ToIDispatch()
+
+		visible, err := oleutil.GetProperty(sheet, "Visible")
+		if err != nil || visible.Val != -1 { // xlSheetVisible = -1
+			sheet.Release()
+			continue
+		}
+
+		name := oleutil.MustGetProperty(sheet, "Name").ToString()
+		pages, err := sheetPrintedPages(sheet)
+		sheet.Release()
+		if err != nil {
+			return nil
+		}
+		counts = append(counts, SheetPageCount{Sheet: name, Pages: pages})
+	}
+
+	return counts
+}
+
+// sheetPrintedPages returns PageSetup.Pages.Count of a worksheet (Excel 2010 or later)
+func sheetPrintedPages(sheet *ole.IDispatch) (int, error) {
+	pageSetupVar, err := oleutil.GetProperty(sheet, "PageSetup")
+	if err != nil {
+		return 0, err
+	}
+	pageSetup := pageSetupVar.ToIDispatch()
+	defer pageSetup.Release()
+
+	pagesVar, err := oleutil.GetProperty(pageSetup, "Pages")
+	if err != nil {
+		return 0, err
+	}
+	pagesObj := pagesVar.ToIDispatch()
+	defer pagesObj.Release()
+
+	countVar, err := oleutil.GetProperty(pagesObj, "Count")
+	if err != nil {
+		return 0, err
+	}
+	return int(countVar.Val), nil
 }
 
 // convertWordToPDF converts Word document to PDF using Word application
@@ -240,8 +410,14 @@ func (c *OfficeConverter) convertWordToPDF(srcPath, outputPath string) error {
 		document.Clear()
 	}()
 
-	// Export as PDF
+	// Refresh INCLUDETEXT, INCLUDEPICTURE and LINK fields from their linked files
 	doc := document.ToIDispatch()
+	if fields, err := oleutil.GetProperty(doc, "Fields"); err == nil {
+		oleutil.CallMethod(fields.ToIDispatch(), "Update")
+		fields.Clear()
+	}
+
+	// Export as PDF
 	_, err = oleutil.CallMethod(doc, "ExportAsFixedFormat", outputPath, 17) // 17 = wdExportFormatPDF
 	if err != nil {
 		return fmt.Errorf("failed to export Word to PDF: %v", err)
@@ -257,12 +433,21 @@ func GetExcelSheetsInfo(filePath string) ([]ExcelSheetInfo, error) {
 		return nil, fmt.Errorf("failed to open Excel file: %v", err)
 	}
 
+	// Sheet IDs let selections follow renamed sheets
+	ids := make(map[string]int)
+	if workbookSheets, err := readWorkbookSheets(filePath); err == nil {
+		for _, sheet := range workbookSheets {
+			ids[sheet.Name] = sheet.SheetID
+		}
+	}
+
 	var sheets []ExcelSheetInfo
 	for i, sheet := range file.Sheets {
 		sheets = append(sheets, ExcelSheetInfo{
 			Name:    sheet.Name,
 			Visible: !sheet.Hidden, // xlsx library uses Hidden property
 			Index:   i,
+			SheetID: ids[sheet.Name],
 		})
 	}
 
@@ -21,10 +21,10 @@ func (a *App) GetInitialDirectory() string {
 // OpenFileDialog opens a file dialog to select PDF files
 func (a *App) OpenFileDialog() (string, error) {
 	file, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "PDFファイルを選択",
+		Title: a.text("dialog.selectPDF"),
 		Filters: []runtime.FileFilter{
 			{
-				DisplayName: "PDFファイル (*.pdf)",
+				DisplayName: a.text("filter.pdf"),
 				Pattern:     "*.pdf",
 			},
 		},
@@ -35,7 +35,7 @@ func (a *App) OpenFileDialog() (string, error) {
 // OpenDirectoryDialog opens a directory selection dialog
 func (a *App) OpenDirectoryDialog() (string, error) {
 	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "フォルダを選択",
+		Title: a.text("dialog.selectFolder"),
 	})
 	return dir, err
 }
@@ -43,7 +43,7 @@ func (a *App) OpenDirectoryDialog() (string, error) {
 // ChangeWorkingDirectory changes the current working directory and emits event
 func (a *App) ChangeWorkingDirectory() (string, error) {
 	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "作業フォルダを選択",
+		Title: a.text("dialog.selectWorkDir"),
 	})
 	if err != nil {
 		return "", err
@@ -51,6 +51,9 @@ func (a *App) ChangeWorkingDirectory() (string, error) {
 	if dir != "" {
 		// Update initial directory
 		a.initialDir = dir
+		a.watchTree(dir)
+		a.restoreWorkspaceOptions()
+		a.refreshWorkspaceMenu()
 
 		// Add to directory history
 		if err := a.AddDirectoryToHistory(dir); err != nil {
@@ -61,7 +64,7 @@ func (a *App) ChangeWorkingDirectory() (string, error) {
 		runtime.WindowSetTitle(a.ctx, fmt.Sprintf("PDF Preview - %s", filepath.Base(dir)))
 
 		// Emit event to notify frontend
-		runtime.EventsEmit(a.ctx, "directory-changed", dir)
+		a.emit(eventDirectoryChanged, dir)
 		return dir, nil
 	}
 	return "", nil
@@ -93,12 +96,12 @@ func (a *App) ShowSaveDialog() error {
 	filePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
 		DefaultDirectory:     filepath.Dir(defaultPath),
 		DefaultFilename:      filepath.Base(defaultPath),
-		Title:                "PDFファイルを保存",
+		Title:                a.text("dialog.savePDF"),
 		ShowHiddenFiles:      false,
 		CanCreateDirectories: true,
 		Filters: []runtime.FileFilter{
 			{
-				DisplayName: "PDFファイル (*.pdf)",
+				DisplayName: a.text("filter.pdf"),
 				Pattern:     "*.pdf",
 			},
 		},
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types published on the App event bus.
+// The names are shared by the Wails runtime events and the SSE stream.
+const (
+	eventConversionProgress = "conversion:progress"
+	eventConversionError    = "conversion:error"
+	eventFileChanged        = "file-changed"
+	eventDirectoryChanged   = "directory-changed"
+	eventSessionChanged     = "session-changed"
+	eventRecipeLoaded       = "recipe-loaded"
+	eventRecipeSaveRequest  = "recipe:save-requested"
+	eventBatchProgress      = "batch:progress"
+	eventBatchCompleted     = "batch:completed"
+	eventBatchOpenRequest   = "batch:open-requested"
+)
+
+// eventHistorySize is how many recent events are kept for resuming streams
+const eventHistorySize = 256
+
+// eventSubscriberBuffer is how many events a slow subscriber may lag behind before it is dropped
+const eventSubscriberBuffer = 64
+
+// FileChangedEvent is the payload of "file-changed"
+type FileChangedEvent struct {
+	File       string `json:"file"`
+	Operation  string `json:"operation"`
+	Dependency string `json:"dependency,omitempty"` // Linked file whose change affects File, if not File itself
+}
+
+// ConversionErrorEvent is the payload of "conversion:error"
+type ConversionErrorEvent struct {
+	Message string `json:"message"`
+}
+
+// RecipeLoadedEvent is the payload of "recipe-loaded"
+type RecipeLoadedEvent struct {
+	Path   string         `json:"path"`
+	Bundle *BundleOptions `json:"bundle"`
+}
+
+// AppEvent is one event on the bus, numbered in publish order
+type AppEvent struct {
+	ID   uint64      `json:"id"`
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// eventBus fans out App events to in-process listeners and stream subscribers.
+// Recent events are kept so a reconnecting stream can resume by event ID.
+type eventBus struct {
+	mu          sync.Mutex
+	nextID      uint64
+	history     []AppEvent
+	listeners   []func(AppEvent)
+	subscribers map[chan AppEvent]struct{}
+}
+
+// newEventBus creates an empty event bus
+func newEventBus() *eventBus {
+	return &eventBus{subscribers: make(map[chan AppEvent]struct{})}
+}
+
+// Listen registers fn to be called synchronously for every published event
+func (b *eventBus) Listen(fn func(AppEvent)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listeners = append(b.listeners, fn)
+}
+
+// Publish numbers an event and delivers it to listeners and subscribers
+func (b *eventBus) Publish(eventType string, data interface{}) AppEvent {
+	b.mu.Lock()
+	b.nextID++
+	event := AppEvent{ID: b.nextID, Type: eventType, Time: time.Now(), Data: data}
+
+	b.history = append(b.history, event)
+	if len(b.history) > eventHistorySize {
+		b.history = b.history[len(b.history)-eventHistorySize:]
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Drop subscribers that cannot keep up; they resume from history on reconnect
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+	listeners := append([]func(AppEvent){}, b.listeners...)
+	b.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(event)
+	}
+	return event
+}
+
+// Subscribe returns the kept events after lastID and a channel for new ones.
+// The channel is closed when cancel is called or the subscriber falls behind.
+func (b *eventBus) Subscribe(lastID uint64) ([]AppEvent, <-chan AppEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var missed []AppEvent
+	for _, event := range b.history {
+		if event.ID > lastID {
+			missed = append(missed, event)
+		}
+	}
+
+	ch := make(chan AppEvent, eventSubscriberBuffer)
+	b.subscribers[ch] = struct{}{}
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, exists := b.subscribers[ch]; exists {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+	return missed, ch, cancel
+}
+
+// emit publishes an App event; the Wails frontend and SSE clients receive it from the bus
+func (a *App) emit(eventType string, data interface{}) {
+	a.events.Publish(eventType, data)
+}
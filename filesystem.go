@@ -27,7 +27,7 @@ func (a *App) GetDirectoryContents(dirPath string) ([]FileInfo, error) {
 
 		// Office files and PDFs only
 		ext := strings.ToLower(filepath.Ext(entry.Name()))
-		if !entry.IsDir() && !isOfficeFile(ext) {
+		if !entry.IsDir() && (!isOfficeFile(ext) || isOfficeTempFile(entry.Name())) {
 			continue
 		}
 
@@ -51,7 +51,7 @@ func (a *App) GetDirectoryTree(dirPath string) ([]FileInfo, error) {
 		return nil, fmt.Errorf("directory path is empty")
 	}
 
-	return a.buildDirectoryTree(dirPath, 0, 3) // Max depth of 3 levels
+	return a.buildDirectoryTree(dirPath, 0, a.currentSettings().TreeDepth)
 }
 
 // buildDirectoryTree recursively builds directory tree
@@ -75,7 +75,7 @@ func (a *App) buildDirectoryTree(dirPath string, currentDepth, maxDepth int) ([]
 		// For directories, always include them
 		// For files, only include Office files and PDFs
 		ext := strings.ToLower(filepath.Ext(entry.Name()))
-		if !entry.IsDir() && !isOfficeFile(ext) {
+		if !entry.IsDir() && (!isOfficeFile(ext) || isOfficeTempFile(entry.Name())) {
 			continue
 		}
 
@@ -129,5 +129,7 @@ func (a *App) GetFileInfo(filePath string) (map[string]interface{}, error) {
 		"path":    filePath,
 		"dir":     filepath.Dir(filePath),
 		"modTime": info.ModTime(),
+		// Linked workbooks and included files whose changes also regenerate this file
+		"dependencies": dependencyInfos(filePath),
 	}, nil
 }
@@ -0,0 +1,58 @@
+// Incremental updates of the directory tree from tree:* events.
+// Every function returns a new tree and leaves the given one unchanged.
+
+// Entries are listed by name, as GetDirectoryTree returns them
+function byName(a, b) {
+  return a.name < b.name ? -1 : a.name > b.name ? 1 : 0
+}
+
+// Whether path lies below dir, with either path separator
+function isInside(path, dir) {
+  return path.startsWith(dir + '\\') || path.startsWith(dir + '/')
+}
+
+// Apply fn to the child list of the directory at parentPath
+function updateChildren(tree, rootPath, parentPath, fn) {
+  if (parentPath === rootPath) {
+    return fn(tree)
+  }
+  return tree.map(node => {
+    if (!node.isDir) return node
+    if (node.path === parentPath) {
+      return { ...node, children: fn(node.children || []) }
+    }
+    if (isInside(parentPath, node.path) && node.children) {
+      return { ...node, children: updateChildren(node.children, node.path, parentPath, fn) }
+    }
+    return node
+  })
+}
+
+// Add or replace an entry under its parent directory
+export function addTreeEntry(tree, rootPath, event) {
+  return updateChildren(tree, rootPath, event.parent, children =>
+    [...children.filter(child => child.path !== event.path), event.file].sort(byName)
+  )
+}
+
+// Remove the entry at event.path
+export function removeTreeEntry(tree, rootPath, event) {
+  return updateChildren(tree, rootPath, event.parent, children =>
+    children.filter(child => child.path !== event.path)
+  )
+}
+
+// Move the entry from event.oldPath to its new place
+export function renameTreeEntry(tree, rootPath, event) {
+  const lastSeparator = Math.max(event.oldPath.lastIndexOf('\\'), event.oldPath.lastIndexOf('/'))
+  const oldParent = event.oldPath.substring(0, lastSeparator)
+  const removed = removeTreeEntry(tree, rootPath, { path: event.oldPath, parent: oldParent })
+  return addTreeEntry(removed, rootPath, event)
+}
+
+// Replace the entry at event.path with its new size and time
+export function modifyTreeEntry(tree, rootPath, event) {
+  return updateChildren(tree, rootPath, event.parent, children =>
+    children.map(child => (child.path === event.path ? event.file : child))
+  )
+}
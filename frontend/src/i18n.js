@@ -0,0 +1,24 @@
+// Window strings in the language of the settings, from the catalog in messages.go
+import { derived, get, writable } from 'svelte/store'
+import { GetMessages } from '../wailsjs/go/main/App.js'
+
+export const messages = writable(/** @type {Record<string, string>} */ ({}))
+
+// Load the catalog for the current language; called at startup and when the settings change
+export async function loadMessages() {
+  messages.set(await GetMessages())
+}
+
+// Fill {name} placeholders; unknown keys show the key itself
+function format(catalog, key, params = {}) {
+  const text = catalog[key] ?? key
+  return text.replace(/\{(\w+)\}/g, (match, name) => (name in params ? String(params[name]) : match))
+}
+
+// For markup: $t('key', { name: value })
+export const t = derived(messages, $messages => (key, params) => format($messages, key, params))
+
+// For script code such as log lines
+export function translate(key, params) {
+  return format(get(messages), key, params)
+}
@@ -1,8 +1,10 @@
 import './style.css'
 import App from './App.svelte'
+import { loadMessages } from './i18n.js'
 
-const app = new App({
-  target: document.getElementById('app')
-})
+// Load the window strings before the first render
+const app = loadMessages()
+  .catch(error => console.warn(`Failed to load messages: ${error}`))
+  .then(() => new App({ target: document.getElementById('app') }))
 
 export default app
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// eventFileDependencies is published when the linked files of an input change
+const eventFileDependencies = "file:dependencies"
+
+// FileDependenciesEvent is the payload of "file:dependencies"
+type FileDependenciesEvent struct {
+	File         string   `json:"file"`
+	Dependencies []string `json:"dependencies"`
+}
+
+// DependencyInfo is a linked file listed in the file info
+type DependencyInfo struct {
+	Path   string `json:"path"`
+	Exists bool   `json:"exists"`
+}
+
+// fieldLinkPattern matches the file argument of Word fields that pull in other files
+var fieldLinkPattern = regexp.MustCompile(`(?i)\b(?:INCLUDETEXT|INCLUDEPICTURE|LINK\s+\S+)\s+(?:\\d\s+)?"([^"]+)"`)
+
+// fieldCodePattern matches the field code text of a Word document: the runs of a complex field
+// after its begin marker, or the instruction of a simple field
+var fieldCodePattern = regexp.MustCompile(`<w:instrText[^>]*>([^<]*)</w:instrText>|w:instr="([^"]*)"|w:fldCharType="begin"`)
+
+// xmlRelationships is an Open Packaging Conventions .rels part
+type xmlRelationships struct {
+	Relationships []struct {
+		Type       string `xml:"Type,attr"`
+		Target     string `xml:"Target,attr"`
+		TargetMode string `xml:"TargetMode,attr"`
+	} `xml:"Relationship"`
+}
+
+// fileDependencies returns the local files an Office document links to:
+// external workbook links of xlsx, and linked files and INCLUDETEXT/INCLUDEPICTURE/LINK fields of docx.
+// Web links are skipped; the paths are absolute and need not exist.
+func fileDependencies(filePath string) ([]string, error) {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	if ext != ".xlsx" && ext != ".xlsm" && ext != ".docx" {
+		return nil, nil
+	}
+
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", filepath.Base(filePath), err)
+	}
+	defer reader.Close()
+
+	var targets []string
+	for _, file := range reader.File {
+		name := file.Name
+		switch {
+		case ext == ".docx" && strings.HasPrefix(name, "word/_rels/") && strings.HasSuffix(name, ".rels"),
+			ext != ".docx" && strings.HasPrefix(name, "xl/externalLinks/_rels/") && strings.HasSuffix(name, ".rels"):
+			found, err := readExternalTargets(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %v", name, err)
+			}
+			targets = append(targets, found...)
+
+		case ext == ".docx" && name == "word/document.xml":
+			found, err := readFieldTargets(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %v", name, err)
+			}
+			targets = append(targets, found...)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var dependencies []string
+	for _, target := range targets {
+		dependency, ok := resolveLinkTarget(filepath.Dir(filePath), target)
+		if !ok || seen[dependency] || dependency == filepath.Clean(filePath) {
+			continue
+		}
+		seen[dependency] = true
+		dependencies = append(dependencies, dependency)
+	}
+	sort.Strings(dependencies)
+	return dependencies, nil
+}
+
+// readExternalTargets returns the targets of the external relationships in a .rels part
+func readExternalTargets(file *zip.File) ([]string, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var rels xmlRelationships
+	if err := xml.NewDecoder(rc).Decode(&rels); err != nil {
+		return nil, err
+	}
+
+	var targets []string
+	for _, rel := range rels.Relationships {
+		// Hyperlinks open in a browser or Office; they do not change the rendered document
+		if rel.TargetMode != "External" || strings.HasSuffix(rel.Type, "/hyperlink") {
+			continue
+		}
+		targets = append(targets, rel.Target)
+	}
+	return targets, nil
+}
+
+// readFieldTargets returns the files named by INCLUDETEXT, INCLUDEPICTURE and LINK fields
+func readFieldTargets(file *zip.File) ([]string, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Long field codes are split over several runs, so join them before matching, one field per line
+	var code strings.Builder
+	for _, match := range fieldCodePattern.FindAllSubmatch(data, -1) {
+		switch {
+		case match[1] != nil:
+			code.WriteString(xmlUnescape(string(match[1])))
+		case match[2] != nil:
+			code.WriteString("\n" + xmlUnescape(string(match[2])) + "\n")
+		default:
+			code.WriteByte('\n')
+		}
+	}
+
+	var targets []string
+	for _, match := range fieldLinkPattern.FindAllStringSubmatch(code.String(), -1) {
+		// Field codes escape backslashes by doubling them
+		targets = append(targets, strings.ReplaceAll(match[1], `\\`, `\`))
+	}
+	return targets, nil
+}
+
+// xmlUnescape decodes the entities of XML character data
+func xmlUnescape(s string) string {
+	var decoded string
+	if err := xml.Unmarshal([]byte("<v>"+s+"</v>"), &decoded); err != nil {
+		return s
+	}
+	return decoded
+}
+
+// resolveLinkTarget turns a link target into an absolute local path.
+// Targets are file URLs, absolute or UNC paths, or paths relative to the linking document.
+func resolveLinkTarget(baseDir, target string) (string, bool) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return "", false
+	}
+
+	lower := strings.ToLower(target)
+	switch {
+	case strings.HasPrefix(lower, "file:"):
+		u, err := url.Parse(target)
+		if err != nil {
+			return "", false
+		}
+		path := u.Path
+		if u.Host != "" {
+			path = `\\` + u.Host + filepath.FromSlash(path) // file://server/share/book.xlsx
+		} else if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+			path = path[1:] // file:///C:/dir/book.xlsx
+		}
+		target = path
+
+	case strings.Contains(target, "://") || strings.HasPrefix(lower, "mailto:"):
+		return "", false
+
+	default:
+		if unescaped, err := url.PathUnescape(target); err == nil {
+			target = unescaped
+		}
+	}
+
+	target = filepath.FromSlash(target)
+	if !filepath.IsAbs(target) && !strings.HasPrefix(target, `\\`) {
+		target = filepath.Join(baseDir, target)
+	}
+	return filepath.Clean(target), true
+}
+
+// linkedFiles returns the linked files of filePath, or none if it cannot be read
+func linkedFiles(filePath string) []string {
+	dependencies, _ := fileDependencies(filePath)
+	return dependencies
+}
+
+// dependencyStamp is the state of a linked file when a cached PDF was made
+type dependencyStamp struct {
+	Size    int64 `json:"size"`
+	ModTime int64 `json:"modTime"` // Unix nanoseconds; zero while the file is missing
+}
+
+// dependencyStamps records the current state of the linked files of filePath
+func dependencyStamps(filePath string) map[string]dependencyStamp {
+	dependencies := linkedFiles(filePath)
+	if len(dependencies) == 0 {
+		return nil
+	}
+
+	stamps := make(map[string]dependencyStamp, len(dependencies))
+	for _, dependency := range dependencies {
+		var stamp dependencyStamp
+		if info, err := os.Stat(dependency); err == nil {
+			stamp = dependencyStamp{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+		}
+		stamps[dependency] = stamp
+	}
+	return stamps
+}
+
+// dependenciesCurrent reports whether the linked files recorded with a cached PDF are unchanged.
+// A PDF cached without a record is only current if the document links to nothing.
+func dependenciesCurrent(meta *conversionMeta, current map[string]dependencyStamp) bool {
+	if meta == nil {
+		return len(current) == 0
+	}
+	return maps.Equal(meta.Dependencies, current)
+}
+
+// dependencyInfos lists the linked files of filePath with whether they exist
+func dependencyInfos(filePath string) []DependencyInfo {
+	dependencies, err := fileDependencies(filePath)
+	if err != nil {
+		return nil
+	}
+
+	infos := make([]DependencyInfo, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		_, err := os.Stat(dependency)
+		infos = append(infos, DependencyInfo{Path: dependency, Exists: err == nil})
+	}
+	return infos
+}
+
+// GetFileDependencies returns the linked files of the watched inputs
+func (a *App) GetFileDependencies() map[string][]string {
+	return a.monitor.Dependencies()
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// writeZip writes an Office package with the given parts
+func writeZip(t *testing.T, filePath string, parts map[string]string) {
+	t.Helper()
+	f, err := os.Create(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for name, content := range parts {
+		part, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := part.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readZipPart opens one part of a package written by writeZip
+func readZipPart(t *testing.T, filePath, name string) *zip.File {
+	t.Helper()
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { reader.Close() })
+	for _, file := range reader.File {
+		if file.Name == name {
+			return file
+		}
+	}
+	t.Fatalf("%s not found in %s", name, filePath)
+	return nil
+}
+
+func TestResolveLinkTarget(t *testing.T) {
+	onWindows, elsewhere := true, false
+	base := filepath.Join(string(filepath.Separator)+"work", "docs")
+	tests := []struct {
+		name    string
+		target  string
+		want    string
+		ok      bool
+		windows *bool // Run only on Windows (true) or elsewhere (false); drive letters and UNC paths are only absolute on Windows
+	}{
+		{name: "empty", target: "  ", ok: false},
+		{name: "web link", target: "https://example.com/book.xlsx", ok: false},
+		{name: "mail link", target: "mailto:someone@example.com", ok: false},
+		{name: "relative", target: "book.xlsx", want: filepath.Join(base, "book.xlsx"), ok: true},
+		{name: "parent directory", target: "../data/book.xlsx", want: filepath.Join(base, "..", "data", "book.xlsx"), ok: true},
+		{name: "escaped relative", target: "sales%20data/book.xlsx", want: filepath.Join(base, "sales data", "book.xlsx"), ok: true},
+		{name: "absolute", target: "/data/book.xlsx", want: "/data/book.xlsx", ok: true, windows: &elsewhere},
+		{name: "file URL", target: "file:///data/sales%20data/book.xlsx", want: "/data/sales data/book.xlsx", ok: true, windows: &elsewhere},
+		{name: "file URL with drive", target: "file:///C:/data/book.xlsx", want: `C:\data\book.xlsx`, ok: true, windows: &onWindows},
+		{name: "file URL with host", target: "file://server/share/book.xlsx", want: `\\server\share\book.xlsx`, ok: true, windows: &onWindows},
+		{name: "drive path", target: `D:\data\book.xlsx`, want: `D:\data\book.xlsx`, ok: true, windows: &onWindows},
+		{name: "UNC path", target: `\\server\share\data\book.xlsx`, want: `\\server\share\data\book.xlsx`, ok: true, windows: &onWindows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.windows != nil && *tt.windows != (runtime.GOOS == "windows") {
+				t.Skipf("not run on %s", runtime.GOOS)
+			}
+			got, ok := resolveLinkTarget(base, tt.target)
+			if ok != tt.ok || got != tt.want {
+				t.Fatalf("resolveLinkTarget(%q) = %q, %v, want %q, %v", tt.target, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestReadFieldTargets(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     []string
+	}{
+		{
+			name:     "simple field",
+			document: `<w:fldSimple w:instr=" INCLUDETEXT &quot;C:\\data\\part.docx&quot; \* MERGEFORMAT "/>`,
+			want:     []string{`C:\data\part.docx`},
+		},
+		{
+			name: "complex field split over runs",
+			document: `<w:r><w:fldChar w:fldCharType="begin"/></w:r>` +
+				`<w:r><w:instrText xml:space="preserve"> INCLUDEPICTURE "images/</w:instrText></w:r>` +
+				`<w:r><w:instrText>logo.png" \d </w:instrText></w:r>` +
+				`<w:r><w:fldChar w:fldCharType="end"/></w:r>`,
+			want: []string{"images/logo.png"},
+		},
+		{
+			name:     "LINK field names its application",
+			document: `<w:fldSimple w:instr=" LINK Excel.Sheet.12 &quot;sales.xlsx&quot; &quot;Sheet1!R1C1:R4C3&quot; \a \f 4 "/>`,
+			want:     []string{"sales.xlsx"},
+		},
+		{
+			name: "fields are matched separately",
+			document: `<w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText> PAGE </w:instrText></w:r>` +
+				`<w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText> INCLUDETEXT "a &amp; b.docx" </w:instrText></w:r>`,
+			want: []string{"a & b.docx"},
+		},
+		{
+			name:     "other fields",
+			document: `<w:fldSimple w:instr=" HYPERLINK &quot;https://example.com&quot; "/><w:fldSimple w:instr=" PAGE "/>`,
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docPath := filepath.Join(t.TempDir(), "doc.docx")
+			writeZip(t, docPath, map[string]string{"word/document.xml": `<w:document><w:body>` + tt.document + `</w:body></w:document>`})
+
+			got, err := readFieldTargets(readZipPart(t, docPath, "word/document.xml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileDependencies(t *testing.T) {
+	dir := t.TempDir()
+	rels := func(targets ...string) string {
+		xml := `<Relationships>`
+		for _, target := range targets {
+			xml += target
+		}
+		return xml + `</Relationships>`
+	}
+
+	workbook := filepath.Join(dir, "summary.xlsx")
+	writeZip(t, workbook, map[string]string{
+		"xl/externalLinks/_rels/externalLink1.xml.rels": rels(
+			`<Relationship Type=".../externalLinkPath" Target="data/sales.xlsx" TargetMode="External"/>`,
+		),
+		"xl/externalLinks/_rels/externalLink2.xml.rels": rels(
+			`<Relationship Type=".../externalLinkPath" Target="data/sales.xlsx" TargetMode="External"/>`,
+			`<Relationship Type=".../externalLinkPath" Target="https://example.com/rates.xlsx" TargetMode="External"/>`,
+		),
+		"xl/_rels/workbook.xml.rels": rels(
+			`<Relationship Type=".../worksheet" Target="worksheets/sheet1.xml"/>`,
+		),
+	})
+
+	document := filepath.Join(dir, "report.docx")
+	writeZip(t, document, map[string]string{
+		"word/_rels/document.xml.rels": rels(
+			`<Relationship Type=".../image" Target="media/image1.png"/>`,
+			`<Relationship Type=".../image" Target="images/chart.png" TargetMode="External"/>`,
+			`<Relationship Type=".../hyperlink" Target="other.docx" TargetMode="External"/>`,
+		),
+		"word/document.xml": `<w:fldSimple w:instr=" INCLUDETEXT &quot;report.docx&quot; "/>` +
+			`<w:fldSimple w:instr=" INCLUDETEXT &quot;parts/intro.docx&quot; "/>`,
+	})
+
+	tests := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{name: "workbook links are listed once", file: workbook, want: []string{filepath.Join(dir, "data", "sales.xlsx")}},
+		{
+			name: "linked images and included files, not hyperlinks or itself",
+			file: document,
+			want: []string{filepath.Join(dir, "images", "chart.png"), filepath.Join(dir, "parts", "intro.docx")},
+		},
+		{name: "legacy formats are not read", file: filepath.Join(dir, "old.xls"), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fileDependencies(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCachedPDFIsStaleWhenLinkedFileChanges(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "sales.xlsx")
+	workbook := filepath.Join(dir, "summary.xlsx")
+	writeZip(t, workbook, map[string]string{
+		"xl/externalLinks/_rels/externalLink1.xml.rels": `<Relationships>` +
+			`<Relationship Type=".../externalLinkPath" Target="sales.xlsx" TargetMode="External"/></Relationships>`,
+	})
+
+	// Missing linked files are recorded too, so creating one makes the cache stale
+	missing := dependencyStamps(workbook)
+	if want := map[string]dependencyStamp{source: {}}; !reflect.DeepEqual(missing, want) {
+		t.Fatalf("stamps %v, want %v", missing, want)
+	}
+
+	if err := os.WriteFile(source, []byte("v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	meta := &conversionMeta{Backend: backendExcel, Dependencies: dependencyStamps(workbook)}
+	if !dependenciesCurrent(meta, dependencyStamps(workbook)) {
+		t.Fatal("unchanged linked file reported as changed")
+	}
+	if dependenciesCurrent(meta, missing) {
+		t.Fatal("created linked file not detected")
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(source, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if dependenciesCurrent(meta, dependencyStamps(workbook)) {
+		t.Fatal("modified linked file not detected")
+	}
+
+	// PDFs cached before links were recorded are only reused for documents without links
+	if dependenciesCurrent(&conversionMeta{Backend: backendExcel}, dependencyStamps(workbook)) {
+		t.Fatal("cache without a record reused for a linked document")
+	}
+	if !dependenciesCurrent(nil, nil) {
+		t.Fatal("cache of a document without links not reused")
+	}
+}
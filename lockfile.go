@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+)
+
+// lockQuietPeriod is how long a file that is open in Office must be quiet before it is regenerated.
+// Office keeps the lock while the document is open, so saves cannot wait for the release alone.
+const lockQuietPeriod = 5 * time.Second
+
+// eventFileEditing is published when an input is opened or closed in Office
+const eventFileEditing = "file:editing"
+
+// FileEditingEvent is the payload of "file:editing"
+type FileEditingEvent struct {
+	File    string `json:"file"`
+	Editing bool   `json:"editing"`
+	Owner   string `json:"owner,omitempty"` // User name stored in the lock file, if known
+}
+
+// isOfficeTempFile reports whether name is an Office or LibreOffice lock or temporary file
+func isOfficeTempFile(name string) bool {
+	return strings.HasPrefix(name, "~$") || strings.HasPrefix(name, ".~")
+}
+
+// officeLockPaths returns where Office and LibreOffice put the lock file of filePath.
+// Word replaces the first one or two characters of long names instead of prefixing them.
+func officeLockPaths(filePath string) []string {
+	dir, name := filepath.Split(filePath)
+	paths := []string{
+		filepath.Join(dir, "~$"+name),
+		filepath.Join(dir, ".~lock."+name+"#"),
+	}
+	runes := []rune(name)
+	switch baseLen := len([]rune(strings.TrimSuffix(name, filepath.Ext(name)))); {
+	case baseLen == 7:
+		paths = append(paths, filepath.Join(dir, "~$"+string(runes[1:])))
+	case baseLen >= 8:
+		paths = append(paths, filepath.Join(dir, "~$"+string(runes[2:])))
+	}
+	return paths
+}
+
+// findOfficeLock returns the lock file of filePath, if one exists
+func findOfficeLock(fs fileStater, filePath string) (string, bool) {
+	for _, lockPath := range officeLockPaths(filePath) {
+		if info, err := fs.Stat(lockPath); err == nil && !info.IsDir() {
+			return lockPath, true
+		}
+	}
+	return "", false
+}
+
+// readLockOwner returns the user name stored in an Office or LibreOffice lock file
+func readLockOwner(fs fileStater, lockPath string) string {
+	data, err := fs.ReadFile(lockPath)
+	if err != nil || len(data) == 0 {
+		return ""
+	}
+
+	// LibreOffice: "LockUser,host,user,time,profile;"
+	if strings.HasPrefix(filepath.Base(lockPath), ".~lock.") {
+		owner, _, _ := strings.Cut(string(data), ",")
+		return strings.TrimSpace(owner)
+	}
+
+	// Office: a length byte and the ANSI name, then at offset 54 a length and the UTF-16 name
+	n := int(data[0])
+	if n == 0 {
+		return ""
+	}
+	if len(data) >= 56+2*n {
+		if wide := int(binary.LittleEndian.Uint16(data[54:56])); wide == n {
+			units := make([]uint16, n)
+			for i := range units {
+				units[i] = binary.LittleEndian.Uint16(data[56+2*i:])
+			}
+			return string(utf16.Decode(units))
+		}
+	}
+	if len(data) >= 1+n {
+		return string(bytes.TrimRight(data[1:1+n], "\x00 "))
+	}
+	return ""
+}
+
+// lockTracker remembers which inputs are open in Office
+type lockTracker struct {
+	mu     sync.Mutex
+	owners map[string]string // Input file -> lock owner
+}
+
+// newLockTracker creates an empty tracker
+func newLockTracker() *lockTracker {
+	return &lockTracker{owners: make(map[string]string)}
+}
+
+// Update records the lock state of filePath and reports whether it changed
+func (t *lockTracker) Update(filePath string, editing bool, owner string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current, locked := t.owners[filePath]
+	if !editing {
+		delete(t.owners, filePath)
+		return locked
+	}
+	t.owners[filePath] = owner
+	return !locked || current != owner
+}
+
+// Locked reports whether filePath is open in Office
+func (t *lockTracker) Locked(filePath string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, locked := t.owners[filePath]
+	return locked
+}
+
+// Owners returns the locked files with their owners
+func (t *lockTracker) Owners() map[string]string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	owners := make(map[string]string, len(t.owners))
+	for filePath, owner := range t.owners {
+		owners[filePath] = owner
+	}
+	return owners
+}
+
+// Retain forgets files that are no longer inputs
+func (t *lockTracker) Retain(filePaths []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	keep := make(map[string]bool)
+	for _, filePath := range filePaths {
+		keep[filePath] = true
+	}
+	for filePath := range t.owners {
+		if !keep[filePath] {
+			delete(t.owners, filePath)
+		}
+	}
+}
+
+// GetEditingFiles returns the inputs currently open in Office with their lock owners
+func (a *App) GetEditingFiles() map[string]string {
+	return a.monitor.locks.Owners()
+}
@@ -9,8 +9,6 @@ import (
 	"path/filepath"
 
 	"github.com/wailsapp/wails/v2"
-	"github.com/wailsapp/wails/v2/pkg/menu"
-	"github.com/wailsapp/wails/v2/pkg/menu/keys"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -20,6 +18,11 @@ import (
 var assets embed.FS
 
 func main() {
+	// Run headless subcommands without starting the window
+	if code, handled := runCommand(os.Args[1:]); handled {
+		os.Exit(code)
+	}
+
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	log.Println("Starting PDF Preview Go application...")
 
@@ -61,23 +64,6 @@ func main() {
 	// Create an instance of the app structure
 	app := NewApp(initialDir)
 
-	// Create application menu
-	appMenu := menu.NewMenu()
-	fileMenu := appMenu.AddSubmenu("ファイル")
-	fileMenu.AddText("フォルダを選択", keys.CmdOrCtrl("o"), func(_ *menu.CallbackData) {
-		app.ChangeWorkingDirectory()
-	})
-	fileMenu.AddSeparator()
-	fileMenu.AddText("PDFを保存", keys.CmdOrCtrl("s"), func(_ *menu.CallbackData) {
-		if err := app.ShowSaveDialog(); err != nil {
-			runtime.LogError(app.ctx, err.Error())
-		}
-	})
-	fileMenu.AddSeparator()
-	fileMenu.AddText("終了", keys.CmdOrCtrl("q"), func(_ *menu.CallbackData) {
-		// App will quit automatically
-	})
-
 	// Create application with options
 	err := wails.Run(&options.App{
 		Title:  "pdf-preview-go",
@@ -86,7 +72,7 @@ func main() {
 		AssetServer: &assetserver.Options{
 			Assets: assets,
 		},
-		Menu:             appMenu,
+		Menu:             app.buildMenu(),
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        app.Startup,
 		OnShutdown:       app.Shutdown,
@@ -95,10 +81,10 @@ func main() {
 				// Show confirmation dialog
 				selection, err := runtime.MessageDialog(ctx, runtime.MessageDialogOptions{
 					Type:          runtime.QuestionDialog,
-					Title:         "未保存の変更があります",
-					Message:       "PDFファイルに未保存の変更があります。保存しますか？",
-					Buttons:       []string{"保存", "保存しない", "キャンセル"},
-					DefaultButton: "保存",
+					Title:         app.text("unsaved.title"),
+					Message:       app.text("unsaved.message"),
+					Buttons:       []string{app.text("button.save"), app.text("button.dontSave"), app.text("button.cancel")},
+					DefaultButton: app.text("button.save"),
 				})
 
 				if err != nil {
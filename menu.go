@@ -0,0 +1,49 @@
+package main
+
+import (
+	"github.com/wailsapp/wails/v2/pkg/menu"
+	"github.com/wailsapp/wails/v2/pkg/menu/keys"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// buildMenu creates the application menu in the language of the settings
+func (a *App) buildMenu() *menu.Menu {
+	appMenu := menu.NewMenu()
+	fileMenu := appMenu.AddSubmenu(a.text("menu.file"))
+	fileMenu.AddText(a.text("menu.selectFolder"), keys.CmdOrCtrl("o"), func(_ *menu.CallbackData) {
+		a.ChangeWorkingDirectory()
+	})
+	fileMenu.AddSeparator()
+	fileMenu.AddText(a.text("menu.openRecipe"), keys.Combo("o", keys.CmdOrCtrlKey, keys.ShiftKey), func(_ *menu.CallbackData) {
+		if _, err := a.OpenRecipeDialog(); err != nil {
+			runtime.LogError(a.ctx, err.Error())
+		}
+	})
+	fileMenu.AddText(a.text("menu.saveRecipe"), keys.Combo("s", keys.CmdOrCtrlKey, keys.ShiftKey), func(_ *menu.CallbackData) {
+		// The selection lives in the frontend, so let it call SaveRecipeDialog
+		a.emit(eventRecipeSaveRequest, nil)
+	})
+	fileMenu.AddText(a.text("menu.batchConvert"), nil, func(_ *menu.CallbackData) {
+		// The frontend asks for the patterns and output folder, then calls BatchConvert
+		a.emit(eventBatchOpenRequest, nil)
+	})
+	fileMenu.AddSeparator()
+	fileMenu.AddText(a.text("menu.savePDF"), keys.CmdOrCtrl("s"), func(_ *menu.CallbackData) {
+		if err := a.ShowSaveDialog(); err != nil {
+			runtime.LogError(a.ctx, err.Error())
+		}
+	})
+	fileMenu.AddSeparator()
+	fileMenu.AddText(a.text("menu.settings"), keys.CmdOrCtrl(","), func(_ *menu.CallbackData) {
+		a.emit(eventSettingsOpenRequest, nil)
+	})
+	fileMenu.AddSeparator()
+	fileMenu.AddText(a.text("menu.quit"), keys.CmdOrCtrl("q"), func(_ *menu.CallbackData) {
+		// App will quit automatically
+	})
+
+	// Recent workspaces of the working directory
+	a.setWorkspaceMenu(appMenu.AddSubmenu(a.text("menu.workspace")))
+
+	return appMenu
+}
@@ -0,0 +1,383 @@
+package main
+
+import "maps"
+
+// messages are the user-facing strings by language: the native menus and dialogs,
+// the window content, which the frontend reads through GetMessages, and the LAN share pages.
+// Placeholders such as {file} are filled in by the frontend.
+var messages = map[string]map[string]string{
+	"ja": {
+		// Native menus and dialogs
+		"menu.file":            "ファイル",
+		"menu.selectFolder":    "フォルダを選択",
+		"menu.openRecipe":      "レシピを開く",
+		"menu.saveRecipe":      "レシピを保存",
+		"menu.batchConvert":    "フォルダ内を個別にPDF変換...",
+		"menu.savePDF":         "PDFを保存",
+		"menu.settings":        "設定...",
+		"menu.quit":            "終了",
+		"menu.workspace":       "ワークスペース",
+		"menu.newWorkspace":    "新しいワークスペース...",
+		"dialog.selectPDF":     "PDFファイルを選択",
+		"dialog.selectFolder":  "フォルダを選択",
+		"dialog.selectWorkDir": "作業フォルダを選択",
+		"dialog.savePDF":       "PDFファイルを保存",
+		"dialog.openRecipe":    "レシピを開く",
+		"dialog.saveRecipe":    "レシピを保存",
+		"filter.pdf":           "PDFファイル (*.pdf)",
+		"filter.recipe":        "レシピファイル (*.json)",
+		"unsaved.title":        "未保存の変更があります",
+		"unsaved.message":      "PDFファイルに未保存の変更があります。保存しますか？",
+		"button.save":          "保存",
+		"button.dontSave":      "保存しない",
+		"button.cancel":        "キャンセル",
+
+		// Window content
+		"button.ok":                   "OK",
+		"button.browse":               "参照...",
+		"autoUpdate.label":            "ファイル変更時に自動更新",
+		"workspace.default":           "標準",
+		"workspace.create":            "新規",
+		"workspace.duplicate":         "複製",
+		"workspace.rename":            "名前変更",
+		"workspace.delete":            "削除",
+		"workspace.new":               "新しいワークスペース",
+		"workspace.namePlaceholder":   "ワークスペース名",
+		"workspace.copyName":          "{name} のコピー",
+		"workspace.confirmDelete":     "ワークスペース「{name}」を削除しますか？",
+		"tree.title":                  "ファイル一覧",
+		"tree.loading":                "ディレクトリを読み込んでいます...",
+		"files.title":                 "選択ファイル",
+		"files.empty":                 "ファイルを選択してください",
+		"files.editing":               "編集中",
+		"files.editingHint":           "Officeで編集中のため、保存後しばらくしてから更新します",
+		"files.sheetIssue":            "シート",
+		"files.sheetIssueHint":        "選択したシートの名前変更・削除があります",
+		"files.linksHint":             "リンク先の変更でも更新します:",
+		"sheets.title":                "シート選択",
+		"sheets.renamed":              "「{sheet}」は「{newName}」に名前が変更されたため、選択を引き継ぎました",
+		"sheets.missing":              "「{sheet}」が見つかりません（削除された可能性があります）",
+		"sheets.dismiss":              "確認",
+		"sheets.hidden":               "(非表示)",
+		"sheets.notFound":             "シートが見つかりません",
+		"sheets.noSheets":             "選択されたファイル（{file}）にはシートがありません",
+		"sheets.selectExcel":          "Excelファイルを選択してください",
+		"convert.button":              "PDFに変換",
+		"convert.running":             "変換中...",
+		"pdf.title":                   "PDFプレビュー",
+		"pdf.unsaved":                 "●未保存",
+		"pdf.share":                   "共有",
+		"pdf.shareHint":               "LAN内のブラウザにプレビューを共有",
+		"pdf.placeholderTitle":        "PDFが生成されるとここに表示されます",
+		"pdf.placeholderText":         "左側でファイルを選択してPDFに変換してください。",
+		"share.active":                "LAN共有中: {urls}",
+		"share.port":                  "ポート {port}",
+		"share.nextCode":              "次の閲覧者のアクセスコード",
+		"share.nextCodeHint":          "コードは1回使うと次のコードに変わります",
+		"share.stop":                  "共有を停止",
+		"log.title":                   "ログ",
+		"log.latest":                  "最新: {log}",
+		"log.empty":                   "ログなし",
+		"settings.title":              "設定",
+		"settings.loadError":          "設定の読み込みでエラー: {error}",
+		"settings.confirmReset":       "設定を既定値に戻しますか？",
+		"settings.reset":              "既定に戻す",
+		"settings.pollingInterval":    "ネットワーク上のファイルの確認間隔（ミリ秒）",
+		"settings.sheetSelectionDays": "シート選択の保存期間（日）",
+		"settings.sessionDays":        "セッションの保存期間（日）",
+		"settings.pdfCacheDays":       "変換済みPDFの保存期間（日）",
+		"settings.treeDepth":          "ファイルツリーの階層数",
+		"settings.httpPort":           "ローカルAPIのポート（0 で自動、再起動後に反映）",
+		"settings.language":           "表示言語",
+		"batch.title":                 "フォルダ内を個別にPDF変換",
+		"batch.rootDir":               "対象フォルダ",
+		"batch.include":               "対象（カンマ区切り、空欄で全てのOfficeファイル）",
+		"batch.exclude":               "除外（カンマ区切り、フォルダも指定可）",
+		"batch.outputDir":             "出力先フォルダ（空欄で元ファイルと同じ場所）",
+		"batch.force":                 "PDFが最新でも変換する",
+		"batch.start":                 "変換",
+		"quit.confirmUnsaved":         "未保存のPDFがあります。保存してからアプリケーションを終了しますか？\n\n「OK」: PDFを保存してから終了\n「キャンセル」: 保存せずに終了\n「×」: 終了をキャンセル",
+
+		// Log lines of the window
+		"log.workDirSet":               "作業ディレクトリを設定しました: {dir}",
+		"log.workDirError":             "作業ディレクトリ取得エラー: {error}",
+		"log.folderChanged":            "作業フォルダを変更しました: {dir}",
+		"log.folderChangedRestored":    "作業フォルダを変更し、前回の状態を復元しました: {dir}",
+		"log.folderLoaded":             "フォルダを読み込みました: {dir}",
+		"log.folderLoadedFlat":         "フォルダを読み込みました (フラット表示): {dir}",
+		"log.folderLoadError":          "フォルダ読み込みエラー: {error}",
+		"log.sessionLoadError":         "セッション状態の読み込みでエラー: {error}",
+		"log.sessionRestoreError":      "セッション復元エラー: {error}",
+		"log.sessionReloaded":          "外部からセッションが変更されたため再読み込みしました",
+		"log.sessionRestored":          "前回の状態を復元しました ({items})",
+		"log.restoredFiles":            "選択ファイル: {count}件",
+		"log.restoredFolders":          "展開フォルダ: {count}件",
+		"log.restoredCurrentFile":      "現在のファイル",
+		"log.restoredSheets":           "シート選択: {count}ファイル",
+		"log.sheetSelectionsLoaded":    "保存されたシート選択を読み込みました ({count}ファイル)",
+		"log.sheetSelectionsLoadError": "シート選択の読み込みでエラー: {error}",
+		"log.sheetSelectionRestored":   "保存されたシート選択を復元: {file} [{sheets}]",
+		"log.sheetsLoaded":             "Excelシートを読み込みました: {file}",
+		"log.sheetsLoadError":          "Excelシート読み込みエラー: {error}",
+		"log.sheetInfoError":           "シート情報取得エラー: {error}",
+		"log.sheetSelected":            "シート選択追加: {sheet}",
+		"log.sheetDeselected":          "シート選択解除: {sheet}",
+		"log.selectedSheets":           "{file}の選択シート: [{sheets}]",
+		"log.sheetRenamed":             "{file}: シート「{sheet}」は「{newName}」に名前が変更されました",
+		"log.sheetMissing":             "{file}: 選択したシート「{sheet}」が見つかりません",
+		"log.fileSelectionChanged":     "ファイル選択更新: {file}",
+		"log.filesReordered":           "ファイル順序を変更しました",
+		"log.fileRemoved":              "ファイルを削除しました: {file}",
+		"log.fileRenamed":              "ファイル名の変更を検出しました: {oldName} → {newName}",
+		"log.fileChanged":              "ファイルが変更されました: {file} - PDFを自動更新中...",
+		"log.linkChanged":              "リンク先が変更されました: {link} ({file}) - PDFを自動更新中...",
+		"log.editing":                  "{file} は編集中です - 保存後しばらくしてから更新します",
+		"log.editingBy":                "{file} は {owner} が編集中です - 保存後しばらくしてから更新します",
+		"log.editingEnded":             "{file} の編集が終了しました",
+		"log.noFilesToConvert":         "変換するファイルが選択されていません",
+		"log.conversionStarted":        "PDF変換を開始します...",
+		"log.fileSheets":               "{file}: 選択されたシート [{sheets}]",
+		"log.fileAllSheets":            "{file}: 全シートを出力",
+		"log.conversionCompleted":      "PDF変換が完了しました: {url}",
+		"log.conversionError":          "PDF変換エラー: {error}",
+		"log.autoUpdateError":          "自動更新エラー: {error}",
+		"log.autoUpdateEnabled":        "自動更新を有効にしました",
+		"log.autoUpdateDisabled":       "自動更新を無効にしました",
+		"log.autoUpdateSettingError":   "自動更新設定エラー: {error}",
+		"log.pdfUpdated":               "PDFが更新されました",
+		"log.pdfUnchanged":             "PDFの内容に変更はありません",
+		"log.noPdfToSave":              "保存するPDFがありません",
+		"log.saveStarted":              "PDFの保存を開始します...",
+		"log.pdfSaved":                 "PDFファイルを保存しました",
+		"log.saveCancelled":            "保存がキャンセルされました",
+		"log.saveError":                "保存エラー: {error}",
+		"log.quitError":                "終了処理エラー: {error}",
+		"log.shareStarted":             "LAN共有を開始しました (アクセスコード: {code})",
+		"log.shareStartError":          "LAN共有の開始でエラー: {error}",
+		"log.shareStopped":             "LAN共有を停止しました",
+		"log.shareStopError":           "LAN共有の停止でエラー: {error}",
+		"log.recipeLoaded":             "レシピを読み込みました: {path}",
+		"log.recipeSaved":              "レシピを保存しました: {path}",
+		"log.recipeSaveCancelled":      "レシピの保存がキャンセルされました",
+		"log.recipeSaveError":          "レシピ保存エラー: {error}",
+		"log.noFilesForRecipe":         "レシピに保存するファイルが選択されていません",
+		"log.batchStarted":             "一括変換を開始しました: {dir}",
+		"log.batchProgress":            "一括変換中 ({progress}%): {file}",
+		"log.batchCompleted":           "一括変換が完了しました: 変換 {converted}件, 最新のためスキップ {skipped}件, 失敗 {failed}件",
+		"log.batchError":               "一括変換エラー: {error}",
+		"log.batchItemError":           "一括変換エラー: {file} - {error}",
+		"log.workspaceListError":       "ワークスペース一覧の取得でエラー: {error}",
+		"log.workspaceSwitched":        "ワークスペースを切り替えました: {name}",
+		"log.workspaceSwitchError":     "ワークスペース切り替えエラー: {error}",
+		"log.workspaceCreateError":     "ワークスペース作成エラー: {error}",
+		"log.workspaceDuplicateError":  "ワークスペース複製エラー: {error}",
+		"log.workspaceRenamed":         "ワークスペース名を変更しました: {name}",
+		"log.workspaceRenameError":     "ワークスペース名の変更でエラー: {error}",
+		"log.workspaceDeleted":         "ワークスペースを削除しました",
+		"log.workspaceDeleteError":     "ワークスペース削除エラー: {error}",
+
+		// LAN share pages
+		"sharePage.title":        "PDFプレビュー",
+		"sharePage.prompt":       "発表者の画面に表示されているアクセスコードを入力してください",
+		"sharePage.wrongCode":    "アクセスコードが違います",
+		"sharePage.view":         "表示",
+		"sharePage.readOnly":     "PDFプレビュー（閲覧専用）",
+		"sharePage.updated":      "更新",
+		"sharePage.disconnected": "接続が切れました。共有が終了した可能性があります",
+	},
+	"en": {
+		// Native menus and dialogs
+		"menu.file":            "File",
+		"menu.selectFolder":    "Open Folder",
+		"menu.openRecipe":      "Open Recipe",
+		"menu.saveRecipe":      "Save Recipe",
+		"menu.batchConvert":    "Convert Each File in Folder to PDF...",
+		"menu.savePDF":         "Save PDF",
+		"menu.settings":        "Settings...",
+		"menu.quit":            "Quit",
+		"menu.workspace":       "Workspace",
+		"menu.newWorkspace":    "New Workspace...",
+		"dialog.selectPDF":     "Select PDF File",
+		"dialog.selectFolder":  "Select Folder",
+		"dialog.selectWorkDir": "Select Working Folder",
+		"dialog.savePDF":       "Save PDF File",
+		"dialog.openRecipe":    "Open Recipe",
+		"dialog.saveRecipe":    "Save Recipe",
+		"filter.pdf":           "PDF files (*.pdf)",
+		"filter.recipe":        "Recipe files (*.json)",
+		"unsaved.title":        "Unsaved changes",
+		"unsaved.message":      "The PDF has unsaved changes. Save it?",
+		"button.save":          "Save",
+		"button.dontSave":      "Don't Save",
+		"button.cancel":        "Cancel",
+
+		// Window content
+		"button.ok":                   "OK",
+		"button.browse":               "Browse...",
+		"autoUpdate.label":            "Update when files change",
+		"workspace.default":           "Default",
+		"workspace.create":            "New",
+		"workspace.duplicate":         "Duplicate",
+		"workspace.rename":            "Rename",
+		"workspace.delete":            "Delete",
+		"workspace.new":               "New workspace",
+		"workspace.namePlaceholder":   "Workspace name",
+		"workspace.copyName":          "Copy of {name}",
+		"workspace.confirmDelete":     "Delete the workspace \"{name}\"?",
+		"tree.title":                  "Files",
+		"tree.loading":                "Loading the folder...",
+		"files.title":                 "Selected Files",
+		"files.empty":                 "Select files",
+		"files.editing":               "Editing",
+		"files.editingHint":           "Open in Office; updated shortly after it is saved",
+		"files.sheetIssue":            "Sheets",
+		"files.sheetIssueHint":        "Selected sheets were renamed or deleted",
+		"files.linksHint":             "Also updated when these linked files change:",
+		"sheets.title":                "Sheets",
+		"sheets.renamed":              "\"{sheet}\" was renamed to \"{newName}\"; it stays selected",
+		"sheets.missing":              "\"{sheet}\" was not found (it may have been deleted)",
+		"sheets.dismiss":              "OK",
+		"sheets.hidden":               "(hidden)",
+		"sheets.notFound":             "No sheets found",
+		"sheets.noSheets":             "The selected file ({file}) has no sheets",
+		"sheets.selectExcel":          "Select an Excel file",
+		"convert.button":              "Convert to PDF",
+		"convert.running":             "Converting...",
+		"pdf.title":                   "PDF Preview",
+		"pdf.unsaved":                 "● Unsaved",
+		"pdf.share":                   "Share",
+		"pdf.shareHint":               "Share the preview with browsers on the LAN",
+		"pdf.placeholderTitle":        "The PDF appears here once it is created",
+		"pdf.placeholderText":         "Select files on the left and convert them to PDF.",
+		"share.active":                "Sharing on the LAN: {urls}",
+		"share.port":                  "port {port}",
+		"share.nextCode":              "Access code for the next viewer",
+		"share.nextCodeHint":          "Each code works once and is then replaced",
+		"share.stop":                  "Stop Sharing",
+		"log.title":                   "Log",
+		"log.latest":                  "Latest: {log}",
+		"log.empty":                   "No log entries",
+		"settings.title":              "Settings",
+		"settings.loadError":          "Failed to load the settings: {error}",
+		"settings.confirmReset":       "Reset the settings to their defaults?",
+		"settings.reset":              "Reset",
+		"settings.pollingInterval":    "Check interval for network files (ms)",
+		"settings.sheetSelectionDays": "Keep sheet selections for (days)",
+		"settings.sessionDays":        "Keep sessions for (days)",
+		"settings.pdfCacheDays":       "Keep converted PDFs for (days)",
+		"settings.treeDepth":          "File tree depth",
+		"settings.httpPort":           "Local API port (0 for automatic; applies after restart)",
+		"settings.language":           "Language",
+		"batch.title":                 "Convert Each File in Folder to PDF",
+		"batch.rootDir":               "Folder",
+		"batch.include":               "Include (comma separated; empty for all Office files)",
+		"batch.exclude":               "Exclude (comma separated; folders allowed)",
+		"batch.outputDir":             "Output folder (empty for next to each file)",
+		"batch.force":                 "Convert even if the PDF is up to date",
+		"batch.start":                 "Convert",
+		"quit.confirmUnsaved":         "The PDF has not been saved. Save it before quitting?\n\n\"OK\": save the PDF, then quit\n\"Cancel\": quit without saving\n\"×\": keep the application open",
+
+		// Log lines of the window
+		"log.workDirSet":               "Working folder set: {dir}",
+		"log.workDirError":             "Failed to get the working folder: {error}",
+		"log.folderChanged":            "Working folder changed: {dir}",
+		"log.folderChangedRestored":    "Working folder changed and the previous state restored: {dir}",
+		"log.folderLoaded":             "Folder loaded: {dir}",
+		"log.folderLoadedFlat":         "Folder loaded (flat): {dir}",
+		"log.folderLoadError":          "Failed to load the folder: {error}",
+		"log.sessionLoadError":         "Failed to load the session: {error}",
+		"log.sessionRestoreError":      "Failed to restore the session: {error}",
+		"log.sessionReloaded":          "The session was changed from outside and has been reloaded",
+		"log.sessionRestored":          "Previous state restored ({items})",
+		"log.restoredFiles":            "selected files: {count}",
+		"log.restoredFolders":          "expanded folders: {count}",
+		"log.restoredCurrentFile":      "current file",
+		"log.restoredSheets":           "sheet selections: {count} files",
+		"log.sheetSelectionsLoaded":    "Saved sheet selections loaded ({count} files)",
+		"log.sheetSelectionsLoadError": "Failed to load the sheet selections: {error}",
+		"log.sheetSelectionRestored":   "Saved sheet selection restored: {file} [{sheets}]",
+		"log.sheetsLoaded":             "Excel sheets loaded: {file}",
+		"log.sheetsLoadError":          "Failed to load the Excel sheets: {error}",
+		"log.sheetInfoError":           "Failed to get the sheets: {error}",
+		"log.sheetSelected":            "Sheet selected: {sheet}",
+		"log.sheetDeselected":          "Sheet deselected: {sheet}",
+		"log.selectedSheets":           "Selected sheets of {file}: [{sheets}]",
+		"log.sheetRenamed":             "{file}: sheet \"{sheet}\" was renamed to \"{newName}\"",
+		"log.sheetMissing":             "{file}: selected sheet \"{sheet}\" was not found",
+		"log.fileSelectionChanged":     "File selection changed: {file}",
+		"log.filesReordered":           "File order changed",
+		"log.fileRemoved":              "File removed: {file}",
+		"log.fileRenamed":              "File renamed: {oldName} → {newName}",
+		"log.fileChanged":              "File changed: {file} - updating the PDF...",
+		"log.linkChanged":              "Linked file changed: {link} ({file}) - updating the PDF...",
+		"log.editing":                  "{file} is being edited - it is updated shortly after it is saved",
+		"log.editingBy":                "{file} is being edited by {owner} - it is updated shortly after it is saved",
+		"log.editingEnded":             "{file} is no longer being edited",
+		"log.noFilesToConvert":         "No files selected for conversion",
+		"log.conversionStarted":        "Starting the PDF conversion...",
+		"log.fileSheets":               "{file}: selected sheets [{sheets}]",
+		"log.fileAllSheets":            "{file}: all sheets",
+		"log.conversionCompleted":      "PDF conversion completed: {url}",
+		"log.conversionError":          "PDF conversion failed: {error}",
+		"log.autoUpdateError":          "Auto-update failed: {error}",
+		"log.autoUpdateEnabled":        "Auto-update turned on",
+		"log.autoUpdateDisabled":       "Auto-update turned off",
+		"log.autoUpdateSettingError":   "Failed to change auto-update: {error}",
+		"log.pdfUpdated":               "PDF updated",
+		"log.pdfUnchanged":             "The PDF content has not changed",
+		"log.noPdfToSave":              "There is no PDF to save",
+		"log.saveStarted":              "Saving the PDF...",
+		"log.pdfSaved":                 "PDF saved",
+		"log.saveCancelled":            "Save cancelled",
+		"log.saveError":                "Failed to save: {error}",
+		"log.quitError":                "Failed while quitting: {error}",
+		"log.shareStarted":             "LAN sharing started (access code: {code})",
+		"log.shareStartError":          "Failed to start LAN sharing: {error}",
+		"log.shareStopped":             "LAN sharing stopped",
+		"log.shareStopError":           "Failed to stop LAN sharing: {error}",
+		"log.recipeLoaded":             "Recipe loaded: {path}",
+		"log.recipeSaved":              "Recipe saved: {path}",
+		"log.recipeSaveCancelled":      "Recipe save cancelled",
+		"log.recipeSaveError":          "Failed to save the recipe: {error}",
+		"log.noFilesForRecipe":         "No files selected to save in the recipe",
+		"log.batchStarted":             "Batch conversion started: {dir}",
+		"log.batchProgress":            "Batch converting ({progress}%): {file}",
+		"log.batchCompleted":           "Batch conversion finished: {converted} converted, {skipped} skipped as up to date, {failed} failed",
+		"log.batchError":               "Batch conversion failed: {error}",
+		"log.batchItemError":           "Batch conversion failed: {file} - {error}",
+		"log.workspaceListError":       "Failed to list the workspaces: {error}",
+		"log.workspaceSwitched":        "Switched to workspace: {name}",
+		"log.workspaceSwitchError":     "Failed to switch workspaces: {error}",
+		"log.workspaceCreateError":     "Failed to create the workspace: {error}",
+		"log.workspaceDuplicateError":  "Failed to duplicate the workspace: {error}",
+		"log.workspaceRenamed":         "Workspace renamed: {name}",
+		"log.workspaceRenameError":     "Failed to rename the workspace: {error}",
+		"log.workspaceDeleted":         "Workspace deleted",
+		"log.workspaceDeleteError":     "Failed to delete the workspace: {error}",
+
+		// LAN share pages
+		"sharePage.title":        "PDF Preview",
+		"sharePage.prompt":       "Enter the access code shown on the presenter's screen",
+		"sharePage.wrongCode":    "Wrong access code",
+		"sharePage.view":         "View",
+		"sharePage.readOnly":     "PDF Preview (read-only)",
+		"sharePage.updated":      "updated",
+		"sharePage.disconnected": "Disconnected. Sharing may have ended",
+	},
+}
+
+// text returns the string key in the language of the settings, falling back to Japanese
+func (a *App) text(key string) string {
+	if s, ok := messages[a.currentSettings().Language][key]; ok {
+		return s
+	}
+	return messages["ja"][key]
+}
+
+// GetMessages returns the strings of the window in the language of the settings.
+// The frontend loads them again when the settings change.
+func (a *App) GetMessages() map[string]string {
+	result := maps.Clone(messages["ja"])
+	maps.Copy(result, messages[a.currentSettings().Language])
+	return result
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"testing"
+)
+
+// placeholderPattern matches the {name} placeholders the frontend fills in
+var placeholderPattern = regexp.MustCompile(`\{\w+\}`)
+
+func TestMessagesCatalogsMatch(t *testing.T) {
+	for _, language := range supportedLanguages {
+		catalog, ok := messages[language]
+		if !ok {
+			t.Fatalf("no catalog for %s", language)
+		}
+		for key, ja := range messages["ja"] {
+			s, ok := catalog[key]
+			if !ok {
+				t.Errorf("%s: missing %q", language, key)
+				continue
+			}
+			// A translation must fill in the same values
+			if got, want := placeholders(s), placeholders(ja); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: %q has placeholders %v, want %v", language, key, got, want)
+			}
+		}
+		for key := range catalog {
+			if _, ok := messages["ja"][key]; !ok {
+				t.Errorf("%s: %q is not in the Japanese catalog", language, key)
+			}
+		}
+	}
+}
+
+func TestGetMessagesFollowsLanguage(t *testing.T) {
+	app := &App{settings: defaultSettings()}
+	app.settings.Language = "en"
+	if got := app.GetMessages()["workspace.default"]; got != "Default" {
+		t.Fatalf("workspace.default = %q, want Default", got)
+	}
+
+	// Unknown languages fall back to Japanese
+	app.settings.Language = "xx"
+	if got := app.GetMessages()["workspace.default"]; got != "標準" {
+		t.Fatalf("workspace.default = %q, want 標準", got)
+	}
+}
+
+func placeholders(s string) []string {
+	found := placeholderPattern.FindAllString(s, -1)
+	sort.Strings(found)
+	return found
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// isNetworkPath reports whether filePath lies on a network mount, where FSEvents misses changes made by other machines
+func isNetworkPath(filePath string) bool {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(filepath.Dir(filePath), &stat); err != nil {
+		return false
+	}
+	switch unix.ByteSliceToString(stat.Fstypename[:]) {
+	case "smbfs", "nfs", "afpfs", "webdav", "cifs":
+		return true
+	}
+	return false
+}
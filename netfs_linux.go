@@ -0,0 +1,28 @@
+package main
+
+import (
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// Network file system magic numbers not defined by x/sys/unix
+const (
+	cifsMagicNumber = 0xff534d42
+	smb2MagicNumber = 0xfe534d42
+)
+
+// isNetworkPath reports whether filePath lies on a network mount, where inotify misses changes made by other machines.
+// 9p is included for WSL drives, which do not report changes made on the Windows side.
+func isNetworkPath(filePath string) bool {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(filepath.Dir(filePath), &stat); err != nil {
+		return false
+	}
+	switch uint32(stat.Type) {
+	case unix.NFS_SUPER_MAGIC, unix.SMB_SUPER_MAGIC, cifsMagicNumber, smb2MagicNumber,
+		unix.V9FS_MAGIC, unix.AFS_SUPER_MAGIC, unix.CODA_SUPER_MAGIC:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !windows
+
+package main
+
+// isNetworkPath cannot detect network mounts on this platform; file system events are trusted
+func isNetworkPath(filePath string) bool {
+	return false
+}
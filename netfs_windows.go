@@ -0,0 +1,25 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// isNetworkPath reports whether filePath lies on a UNC path or a mapped network drive,
+// where ReadDirectoryChangesW misses changes made by other machines
+func isNetworkPath(filePath string) bool {
+	volume := filepath.VolumeName(filePath)
+	if strings.HasPrefix(volume, `\\`) {
+		return true
+	}
+	if volume == "" {
+		return false
+	}
+	root, err := windows.UTF16PtrFromString(volume + `\`)
+	if err != nil {
+		return false
+	}
+	return windows.GetDriveType(root) == windows.DRIVE_REMOTE
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// outputTTL is how long a registered output stays reachable after its last access
+const outputTTL = time.Hour
+
+// previewSlot is the output slot of the GUI preview
+const previewSlot = "preview"
+
+// outputSnapshotsDir is the directory below the cache that holds the published copies
+const outputSnapshotsDir = "outputs"
+
+// outputRegistry maps opaque IDs to the files the HTTP server may serve.
+// Nothing outside the registry, such as session files or other cached PDFs, is reachable.
+// Each output slot keeps one ID, so the viewer can revalidate the same URL by ETag.
+//
+// Published files are copied into a directory owned by the registry and named by their hash,
+// so a cached PDF that is converted again later never changes bytes served under an old ETag.
+type outputRegistry struct {
+	mu      sync.Mutex
+	entries map[string]*registeredOutput
+	slots   map[string]string // Slot name -> ID
+	ttl     time.Duration
+	baseDir string // Parent of dir, shared with other app instances
+	dir     string // Snapshots of this instance; created on first Publish
+}
+
+// registeredOutput is a file exposed under an opaque ID
+type registeredOutput struct {
+	path    string // Snapshot owned by the registry
+	sha256  string // Content hash, used as the strong ETag
+	expires time.Time
+}
+
+// newOutputRegistry creates an empty registry whose entries expire after ttl without access.
+// Snapshots are kept in a directory of their own below baseDir.
+func newOutputRegistry(ttl time.Duration, baseDir string) *outputRegistry {
+	return &outputRegistry{
+		entries: make(map[string]*registeredOutput),
+		slots:   make(map[string]string),
+		ttl:     ttl,
+		baseDir: baseDir,
+	}
+}
+
+// Publish copies a file into the registry and exposes it in an output slot under the slot's ID.
+// changed is false when the slot already served identical bytes.
+func (r *outputRegistry) Publish(slot, filePath string) (id string, changed bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshotPath, sha256, err := r.snapshotLocked(filePath)
+	if err != nil {
+		return "", false, err
+	}
+
+	now := time.Now()
+	r.pruneLocked(now)
+
+	if id, exists := r.slots[slot]; exists {
+		entry := r.entries[id]
+		changed = entry.sha256 != sha256
+		entry.path = snapshotPath
+		entry.sha256 = sha256
+		entry.expires = now.Add(r.ttl)
+		r.removeUnusedLocked()
+		return id, changed, nil
+	}
+
+	id, err = randomHex(16)
+	if err != nil {
+		return "", false, err
+	}
+	r.entries[id] = &registeredOutput{path: snapshotPath, sha256: sha256, expires: now.Add(r.ttl)}
+	r.slots[slot] = id
+	return id, true, nil
+}
+
+// Share exposes the file currently registered under id in another slot as well, and returns the slot's ID.
+// The slot keeps those bytes when id is published again.
+func (r *outputRegistry) Share(id, slot string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.pruneLocked(now)
+
+	entry, exists := r.entries[id]
+	if !exists {
+		return "", false
+	}
+
+	slotID, exists := r.slots[slot]
+	if !exists {
+		var err error
+		if slotID, err = randomHex(16); err != nil {
+			return "", false
+		}
+		r.slots[slot] = slotID
+	}
+	r.entries[slotID] = &registeredOutput{path: entry.path, sha256: entry.sha256, expires: now.Add(r.ttl)}
+	r.removeUnusedLocked()
+	return slotID, true
+}
+
+// snapshotLocked copies a file into the registry directory under its content hash; the caller must hold r.mu.
+// The hash is taken from the copy, so the ETag always matches the bytes that are served.
+func (r *outputRegistry) snapshotLocked(filePath string) (string, string, error) {
+	if r.dir == "" {
+		if err := os.MkdirAll(r.baseDir, 0755); err != nil {
+			return "", "", fmt.Errorf("failed to create output directory: %v", err)
+		}
+		dir, err := os.MkdirTemp(r.baseDir, "instance-")
+		if err != nil {
+			return "", "", fmt.Errorf("failed to create output directory: %v", err)
+		}
+		r.dir = dir
+	}
+
+	tmp, err := os.CreateTemp(r.dir, "publish-*.tmp")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to copy output: %v", err)
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	if err := copyFile(filePath, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return "", "", fmt.Errorf("failed to copy output: %v", err)
+	}
+
+	sha256, _, err := hashFile(tmpPath)
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", "", err
+	}
+
+	// Identical bytes are already in place; the existing file is never rewritten
+	snapshotPath := filepath.Join(r.dir, sha256+".pdf")
+	if _, err := os.Stat(snapshotPath); err == nil {
+		os.Remove(tmpPath)
+		return snapshotPath, sha256, nil
+	}
+	if err := os.Rename(tmpPath, snapshotPath); err != nil {
+		os.Remove(tmpPath)
+		return "", "", fmt.Errorf("failed to copy output: %v", err)
+	}
+	return snapshotPath, sha256, nil
+}
+
+// removeUnusedLocked deletes snapshots no entry refers to; the caller must hold r.mu.
+// A snapshot still being sent cannot be deleted on Windows and is retried on the next call.
+func (r *outputRegistry) removeUnusedLocked() {
+	if r.dir == "" {
+		return
+	}
+	used := make(map[string]bool, len(r.entries))
+	for _, entry := range r.entries {
+		used[entry.path] = true
+	}
+
+	files, err := os.ReadDir(r.dir)
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		filePath := filepath.Join(r.dir, file.Name())
+		if !used[filePath] && strings.HasSuffix(file.Name(), ".pdf") {
+			os.Remove(filePath)
+		}
+	}
+}
+
+// Close removes the snapshots of this instance
+func (r *outputRegistry) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.dir != "" {
+		os.RemoveAll(r.dir)
+		r.dir = ""
+	}
+}
+
+// cleanupOutputSnapshots removes snapshot directories left by instances that did not shut down
+func cleanupOutputSnapshots(baseDir string, maxAge time.Duration) error {
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if info, err := entry.Info(); err == nil && info.ModTime().Before(cutoff) {
+			os.RemoveAll(filepath.Join(baseDir, entry.Name()))
+		}
+	}
+	return nil
+}
+
+// Lookup returns the file registered under id and extends its lifetime
+func (r *outputRegistry) Lookup(id string) (registeredOutput, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.pruneLocked(now)
+
+	entry, exists := r.entries[id]
+	if !exists {
+		return registeredOutput{}, false
+	}
+	entry.expires = now.Add(r.ttl)
+	return *entry, true
+}
+
+// Current returns the file currently published in a slot and extends its lifetime
+func (r *outputRegistry) Current(slot string) (registeredOutput, bool) {
+	r.mu.Lock()
+	id, exists := r.slots[slot]
+	r.mu.Unlock()
+	if !exists {
+		return registeredOutput{}, false
+	}
+	return r.Lookup(id)
+}
+
+// pruneLocked removes expired entries, their slots and their snapshots; the caller must hold r.mu
+func (r *outputRegistry) pruneLocked(now time.Time) {
+	expired := false
+	for id, entry := range r.entries {
+		if now.After(entry.expires) {
+			delete(r.entries, id)
+			expired = true
+		}
+	}
+	for slot, id := range r.slots {
+		if _, exists := r.entries[id]; !exists {
+			delete(r.slots, slot)
+		}
+	}
+	if expired {
+		r.removeUnusedLocked()
+	}
+}
+
+// ServeHTTP serves /pdf/<id> for registered outputs only
+func (r *outputRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	id := strings.TrimPrefix(req.URL.Path, "/pdf/")
+	entry, exists := r.Lookup(id)
+	if !exists {
+		http.NotFound(w, req)
+		return
+	}
+
+	servePDF(w, req, entry.path, entry.sha256)
+}
+
+// servePDF serves a PDF file with Range support.
+// With a content hash it sends a strong ETag, so clients revalidate with If-None-Match
+// and resume with If-Range; without one the response is not cached.
+func servePDF(w http.ResponseWriter, req *http.Request, filePath, sha256 string) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, req)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	if sha256 != "" {
+		w.Header().Set("ETag", `"`+sha256+`"`)
+		w.Header().Set("Cache-Control", "no-cache")
+	} else {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	http.ServeContent(w, req, "", info.ModTime(), f)
+}
@@ -46,6 +46,11 @@ func (a *App) SavePdfAs(savePath string) error {
 
 // GetDefaultSavePath returns the default save path based on initial directory or file
 func (a *App) GetDefaultSavePath() string {
+	// An open recipe defines its own output path
+	if recipe := a.activeRecipeOptions(); recipe != nil && recipe.OutputPath != "" {
+		return recipe.OutputPath
+	}
+
 	if a.initialDir == "" {
 		return ""
 	}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"crypto/md5"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// ProgressFunc receives progress updates while a bundle is being built
+type ProgressFunc func(status ConversionStatus)
+
+// PostProcessOptions controls what happens to the merged PDF
+type PostProcessOptions struct {
+	Bookmarks    bool `json:"bookmarks"`    // Add one bookmark per input named after the file
+	DividerPages bool `json:"dividerPages"` // Insert a blank page between inputs
+	Optimize     bool `json:"optimize"`     // Optimize the final PDF
+}
+
+// BundleOptions describes a set of input files to be turned into one PDF
+type BundleOptions struct {
+	Files           []string            `json:"files"`           // Input files in output order
+	SheetSelections map[string][]string `json:"sheetSelections"` // File path -> selected sheets
+	PageSelections  map[string]string   `json:"pageSelections"`  // File path -> page selection such as "1-3,5"
+	PostProcess     PostProcessOptions  `json:"postProcess"`     // Options applied after merging
+	OutputPath      string              `json:"outputPath"`      // Destination PDF; empty keeps the result in the cache directory
+	Changed         []string            `json:"-"`               // Inputs known to have changed; the others reuse up-to-date cached PDFs
+}
+
+// BundleResult summarizes the outcome of a bundle build
+type BundleResult struct {
+	OutputPath string            // Path of the produced PDF
+	Converted  []string          // Input files that were converted successfully
+	Errors     []string          // Per-file error messages for inputs that failed
+	Report     *ConversionReport // Machine-readable details of the run
+	outputID   string            // Registry ID the preview was published under; set by convertBundle
+}
+
+// Partial reports whether some, but not all, inputs failed
+func (r *BundleResult) Partial() bool {
+	return len(r.Errors) > 0 && len(r.Converted) > 0
+}
+
+// buildBundle converts each input with the converter and merges the results.
+// It is shared by the GUI and the headless command line modes.
+// The returned result, and its report, is non-nil even when the build fails.
+func buildBundle(converter *OfficeConverter, opts BundleOptions, progress ProgressFunc) (result *BundleResult, err error) {
+	result = &BundleResult{Report: newConversionReport()}
+	defer func() {
+		result.Report.finish(result.OutputPath, err)
+	}()
+
+	if len(opts.Files) == 0 {
+		return result, fmt.Errorf("no files selected for conversion")
+	}
+	if progress == nil {
+		progress = func(ConversionStatus) {}
+	}
+
+	var convertedPDFs []string
+	var titles []string
+	var convertedInputs []int // Indexes into result.Report.Inputs
+
+	changed := make(map[string]bool)
+	for _, filePath := range opts.Changed {
+		changed[filepath.Clean(filePath)] = true
+	}
+
+	// Convert each file to PDF
+	for i, filePath := range opts.Files {
+		progress(ConversionStatus{
+			Status:      "running",
+			CurrentFile: filepath.Base(filePath),
+			Progress:    int((float64(i) / float64(len(opts.Files))) * 100),
+		})
+
+		start := time.Now()
+		input := InputReport{
+			Path:   filePath,
+			Status: "failed",
+			Sheets: opts.SheetSelections[filePath],
+			Pages:  opts.PageSelections[filePath],
+		}
+
+		// The cache is keyed by file and sheets and checked against the file time;
+		// inputs reported as changed are reconverted regardless
+		converted := converter.Convert(filePath, opts.SheetSelections, changed[filepath.Clean(filePath)])
+		input.Backend = converted.Backend
+		input.CacheHit = converted.CacheHit
+		input.Warnings = converted.Warnings
+		outputPath, err := converted.OutputPath, converted.Error
+
+		// Keep only the requested pages
+		if err == nil && input.Pages != "" {
+			outputPath, err = selectPages(outputPath, input.Pages)
+		}
+
+		if err == nil {
+			input.PageCount, err = api.PageCountFile(outputPath)
+		}
+
+		input.DurationMs = time.Since(start).Milliseconds()
+		if err != nil {
+			input.Error = err.Error()
+			result.Report.Inputs = append(result.Report.Inputs, input)
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", filepath.Base(filePath), err))
+			continue
+		}
+
+		input.Status = "converted"
+		if input.Pages == "" {
+			input.SheetPages = sheetPageRanges(converted.SheetPages, input.PageCount)
+		}
+		if converted.SheetPages != nil && input.SheetPages == nil {
+			input.Warnings = append(input.Warnings, "sheet page mapping is not available")
+		}
+		result.Report.Inputs = append(result.Report.Inputs, input)
+		convertedInputs = append(convertedInputs, len(result.Report.Inputs)-1)
+
+		convertedPDFs = append(convertedPDFs, outputPath)
+		titles = append(titles, filepath.Base(filePath))
+		result.Converted = append(result.Converted, filePath)
+	}
+
+	if len(convertedPDFs) == 0 {
+		return result, fmt.Errorf("no files were successfully converted: %v", result.Errors)
+	}
+
+	// Place each input in the output page numbering
+	page := 1
+	for _, index := range convertedInputs {
+		input := &result.Report.Inputs[index]
+		input.FirstPage = page
+		input.LastPage = page + input.PageCount - 1
+		for i := range input.SheetPages {
+			input.SheetPages[i].FirstPage += page - 1
+			input.SheetPages[i].LastPage += page - 1
+		}
+		page += input.PageCount
+		if opts.PostProcess.DividerPages && len(convertedInputs) > 1 {
+			page++
+		}
+	}
+
+	// A single file without an explicit destination or post-processing is served straight from the cache
+	if len(convertedPDFs) == 1 && opts.OutputPath == "" && opts.PostProcess == (PostProcessOptions{}) {
+		result.OutputPath = convertedPDFs[0]
+		return result, nil
+	}
+
+	if len(convertedPDFs) > 1 {
+		progress(ConversionStatus{
+			Status:      "running",
+			CurrentFile: "merging PDFs",
+			Progress:    90,
+		})
+	}
+
+	outputPath := opts.OutputPath
+	if outputPath == "" {
+		// Generate merged PDF filename with timestamp
+		timestamp := time.Now().Format("20060102_150405")
+		outputPath = filepath.Join(converter.cacheDir, fmt.Sprintf("merged_%s.pdf", timestamp))
+	}
+
+	if err := mergeBundle(convertedPDFs, titles, outputPath, opts.PostProcess); err != nil {
+		return result, err
+	}
+
+	result.OutputPath = outputPath
+	return result, nil
+}
+
+// sheetPageRanges turns per-sheet page counts into page ranges of the converted PDF.
+// It returns nil when the counts do not add up to the PDF page count.
+func sheetPageRanges(counts []SheetPageCount, pageCount int) []SheetPageRange {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	var ranges []SheetPageRange
+	page := 1
+	for _, count := range counts {
+		if count.Pages == 0 {
+			continue
+		}
+		ranges = append(ranges, SheetPageRange{
+			Sheet:     count.Sheet,
+			FirstPage: page,
+			LastPage:  page + count.Pages - 1,
+		})
+		page += count.Pages
+	}
+
+	if page-1 != pageCount {
+		return nil
+	}
+	return ranges
+}
+
+// selectPages writes the selected pages of a converted PDF to a sibling cache file
+func selectPages(pdfPath, pages string) (string, error) {
+	selection, err := api.ParsePageSelection(pages)
+	if err != nil {
+		return "", fmt.Errorf("invalid page selection %q: %v", pages, err)
+	}
+
+	hash := md5.Sum([]byte(pages))
+	outputPath := fmt.Sprintf("%s_p%x.pdf", strings.TrimSuffix(pdfPath, filepath.Ext(pdfPath)), hash[:4])
+	if err := api.TrimFile(pdfPath, outputPath, selection, nil); err != nil {
+		return "", fmt.Errorf("failed to select pages %q: %v", pages, err)
+	}
+	return outputPath, nil
+}
+
+// mergeBundle merges the converted PDFs into outputPath and applies the post-processing options
+func mergeBundle(inputPaths, titles []string, outputPath string, opts PostProcessOptions) error {
+	if len(inputPaths) == 1 {
+		if err := copyFile(inputPaths[0], outputPath); err != nil {
+			return fmt.Errorf("failed to copy PDF: %v", err)
+		}
+	} else {
+		// Bookmarks named after cache files are useless, so they are added separately below
+		conf := model.NewDefaultConfiguration()
+		conf.CreateBookmarks = false
+		if err := api.MergeCreateFile(inputPaths, outputPath, opts.DividerPages, conf); err != nil {
+			return fmt.Errorf("failed to merge PDFs: %v", err)
+		}
+	}
+
+	if opts.Bookmarks {
+		var bookmarks []pdfcpu.Bookmark
+		page := 1
+		for i, inputPath := range inputPaths {
+			pageCount, err := api.PageCountFile(inputPath)
+			if err != nil {
+				return fmt.Errorf("failed to count pages of %s: %v", titles[i], err)
+			}
+			bookmarks = append(bookmarks, pdfcpu.Bookmark{Title: titles[i], PageFrom: page})
+			page += pageCount
+			if opts.DividerPages {
+				page++
+			}
+		}
+		if err := api.AddBookmarksFile(outputPath, "", bookmarks, true, nil); err != nil {
+			return fmt.Errorf("failed to add bookmarks: %v", err)
+		}
+	}
+
+	if opts.Optimize {
+		if err := api.OptimizeFile(outputPath, "", nil); err != nil {
+			return fmt.Errorf("failed to optimize PDF: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// buildBundleAtomically builds the bundle into a temporary file next to opts.OutputPath
+// and renames it into place, so viewers never see a partially written PDF.
+func buildBundleAtomically(converter *OfficeConverter, opts BundleOptions, progress ProgressFunc) (*BundleResult, error) {
+	if opts.OutputPath == "" {
+		return nil, fmt.Errorf("output path is empty")
+	}
+	finalPath := opts.OutputPath
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(finalPath), "."+filepath.Base(finalPath)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+	defer os.Remove(tmpPath) // No-op after a successful rename
+
+	opts.OutputPath = tmpPath
+	result, err := buildBundle(converter, opts, progress)
+	if err != nil {
+		return result, err
+	}
+
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		return result, fmt.Errorf("failed to replace %s: %v", finalPath, err)
+	}
+	result.OutputPath = finalPath
+	if result.Report.Output != nil {
+		result.Report.Output.Path = finalPath
+	}
+	return result, nil
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// recipeVersion is the current recipe file format version
+const recipeVersion = 1
+
+// Recipe describes a reproducible PDF bundle. Paths are relative to the recipe file
+// and stored with forward slashes so the file can be committed and shared.
+type Recipe struct {
+	Version int                `json:"version"`          // Recipe format version
+	Output  string             `json:"output,omitempty"` // Output PDF path
+	Inputs  []RecipeInput      `json:"inputs"`           // Input files in output order
+	Options PostProcessOptions `json:"options"`          // Post-processing options
+}
+
+// RecipeInput describes one input file of a recipe
+type RecipeInput struct {
+	Path   string   `json:"path"`             // File path
+	Sheets []string `json:"sheets,omitempty"` // Selected Excel sheets; empty exports all
+	Pages  string   `json:"pages,omitempty"`  // Page selection such as "1-3,5"; empty keeps all
+}
+
+// LoadRecipe reads and validates a recipe file
+func LoadRecipe(recipePath string) (*Recipe, error) {
+	data, err := os.ReadFile(recipePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recipe: %v", err)
+	}
+
+	var recipe Recipe
+	if err := json.Unmarshal(data, &recipe); err != nil {
+		return nil, fmt.Errorf("failed to parse recipe: %v", err)
+	}
+
+	if recipe.Version < 1 || recipe.Version > recipeVersion {
+		return nil, fmt.Errorf("unsupported recipe version: %d", recipe.Version)
+	}
+	if len(recipe.Inputs) == 0 {
+		return nil, fmt.Errorf("recipe has no inputs")
+	}
+	for i, input := range recipe.Inputs {
+		if input.Path == "" {
+			return nil, fmt.Errorf("recipe input %d has no path", i+1)
+		}
+	}
+
+	return &recipe, nil
+}
+
+// SaveRecipe writes a recipe file
+func SaveRecipe(recipePath string, recipe *Recipe) error {
+	recipe.Version = recipeVersion
+
+	data, err := json.MarshalIndent(recipe, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal recipe: %v", err)
+	}
+
+	if err := writeFileAtomic(recipePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write recipe: %v", err)
+	}
+
+	return nil
+}
+
+// BundleOptions resolves the recipe against the directory of the recipe file
+func (r *Recipe) BundleOptions(recipePath string) BundleOptions {
+	baseDir := filepath.Dir(recipePath)
+	if absDir, err := filepath.Abs(baseDir); err == nil {
+		baseDir = absDir
+	}
+
+	opts := BundleOptions{
+		SheetSelections: make(map[string][]string),
+		PageSelections:  make(map[string]string),
+		PostProcess:     r.Options,
+	}
+	if r.Output != "" {
+		opts.OutputPath = resolveRecipePath(baseDir, r.Output)
+	}
+
+	for _, input := range r.Inputs {
+		filePath := resolveRecipePath(baseDir, input.Path)
+		opts.Files = append(opts.Files, filePath)
+		if len(input.Sheets) > 0 {
+			opts.SheetSelections[filePath] = input.Sheets
+		}
+		if input.Pages != "" {
+			opts.PageSelections[filePath] = input.Pages
+		}
+	}
+
+	return opts
+}
+
+// NewRecipe creates a recipe from bundle options, relative to the recipe file location
+func NewRecipe(recipePath string, opts BundleOptions) *Recipe {
+	baseDir := filepath.Dir(recipePath)
+	if absDir, err := filepath.Abs(baseDir); err == nil {
+		baseDir = absDir
+	}
+
+	recipe := &Recipe{
+		Version: recipeVersion,
+		Options: opts.PostProcess,
+		Inputs:  []RecipeInput{},
+	}
+	if opts.OutputPath != "" {
+		recipe.Output = relativeRecipePath(baseDir, opts.OutputPath)
+	}
+
+	for _, filePath := range opts.Files {
+		recipe.Inputs = append(recipe.Inputs, RecipeInput{
+			Path:   relativeRecipePath(baseDir, filePath),
+			Sheets: opts.SheetSelections[filePath],
+			Pages:  opts.PageSelections[filePath],
+		})
+	}
+
+	return recipe
+}
+
+// resolveRecipePath converts a recipe path to an absolute OS path
+func resolveRecipePath(baseDir, path string) string {
+	path = filepath.FromSlash(path)
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// relativeRecipePath converts an absolute path to a portable recipe path
+func relativeRecipePath(baseDir, path string) string {
+	if rel, err := filepath.Rel(baseDir, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	// Different volume: keep the absolute path
+	return filepath.ToSlash(path)
+}
+
+// LoadRecipeFile loads a recipe and makes its page selections, options and output path active
+func (a *App) LoadRecipeFile(recipePath string) (*BundleOptions, error) {
+	recipe, err := LoadRecipe(recipePath)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := recipe.BundleOptions(recipePath)
+	a.setRecipe(recipePath, &opts)
+
+	return &opts, nil
+}
+
+// SaveRecipeFile saves the given selection, together with the active recipe options, as a recipe
+func (a *App) SaveRecipeFile(recipePath string, filePaths []string, sheetSelections map[string][]string) error {
+	if len(filePaths) == 0 {
+		return fmt.Errorf("no files selected")
+	}
+
+	opts := a.currentBundleOptions(filePaths, sheetSelections)
+	if err := SaveRecipe(recipePath, NewRecipe(recipePath, opts)); err != nil {
+		return err
+	}
+
+	a.setRecipe(recipePath, &opts)
+	return nil
+}
+
+// OpenRecipeDialog shows an open dialog and loads the selected recipe
+func (a *App) OpenRecipeDialog() (*BundleOptions, error) {
+	recipePath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:   a.text("dialog.openRecipe"),
+		Filters: a.recipeFileFilters(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if recipePath == "" {
+		return nil, nil
+	}
+
+	opts, err := a.LoadRecipeFile(recipePath)
+	if err != nil {
+		return nil, err
+	}
+
+	a.emit(eventRecipeLoaded, RecipeLoadedEvent{Path: recipePath, Bundle: opts})
+	return opts, nil
+}
+
+// SaveRecipeDialog shows a save dialog and writes the given selection as a recipe
+func (a *App) SaveRecipeDialog(filePaths []string, sheetSelections map[string][]string) (string, error) {
+	a.recipeMu.Lock()
+	defaultPath := a.recipePath
+	a.recipeMu.Unlock()
+	if defaultPath == "" {
+		if savePath := a.GetDefaultSavePath(); savePath != "" {
+			defaultPath = strings.TrimSuffix(savePath, filepath.Ext(savePath)) + ".recipe.json"
+		}
+	}
+
+	recipePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		DefaultDirectory:     filepath.Dir(defaultPath),
+		DefaultFilename:      filepath.Base(defaultPath),
+		Title:                a.text("dialog.saveRecipe"),
+		CanCreateDirectories: true,
+		Filters:              a.recipeFileFilters(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to show save dialog: %v", err)
+	}
+	if recipePath == "" {
+		return "", fmt.Errorf("user_cancelled")
+	}
+
+	if err := a.SaveRecipeFile(recipePath, filePaths, sheetSelections); err != nil {
+		return "", err
+	}
+	return recipePath, nil
+}
+
+// currentBundleOptions combines a file selection with the active recipe options
+func (a *App) currentBundleOptions(filePaths []string, sheetSelections map[string][]string) BundleOptions {
+	opts := BundleOptions{
+		Files:           filePaths,
+		SheetSelections: sheetSelections,
+		PageSelections:  make(map[string]string),
+	}
+	if recipe := a.activeRecipeOptions(); recipe != nil {
+		opts.PostProcess = recipe.PostProcess
+		opts.OutputPath = recipe.OutputPath
+		for _, filePath := range filePaths {
+			if pages, exists := recipe.PageSelections[filePath]; exists {
+				opts.PageSelections[filePath] = pages
+			}
+		}
+	}
+	return opts
+}
+
+// activeRecipeOptions returns a copy of the active recipe options, or nil if there are none
+func (a *App) activeRecipeOptions() *BundleOptions {
+	a.recipeMu.Lock()
+	defer a.recipeMu.Unlock()
+
+	if a.recipeOptions == nil {
+		return nil
+	}
+	opts := *a.recipeOptions
+	opts.PageSelections = maps.Clone(opts.PageSelections)
+	return &opts
+}
+
+// setRecipe makes a copy of opts the active recipe options; an empty recipePath keeps the current one
+func (a *App) setRecipe(recipePath string, opts *BundleOptions) {
+	if opts != nil {
+		copied := *opts
+		copied.PageSelections = maps.Clone(opts.PageSelections)
+		if copied.PageSelections == nil {
+			copied.PageSelections = make(map[string]string)
+		}
+		opts = &copied
+	}
+
+	a.recipeMu.Lock()
+	defer a.recipeMu.Unlock()
+
+	if recipePath != "" {
+		a.recipePath = recipePath
+	}
+	a.recipeOptions = opts
+}
+
+// recipeFileFilters returns the dialog filters for recipe files
+func (a *App) recipeFileFilters() []runtime.FileFilter {
+	return []runtime.FileFilter{
+		{
+			DisplayName: a.text("filter.recipe"),
+			Pattern:     "*.json",
+		},
+	}
+}
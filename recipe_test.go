@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestActiveRecipeOptionsAreCopies(t *testing.T) {
+	app := &App{}
+	opts := &BundleOptions{PageSelections: map[string]string{"a.xlsx": "1"}, OutputPath: "out.pdf"}
+	app.setRecipe("bundle.recipe.json", opts)
+
+	// Neither the caller's value nor a returned copy changes the active options
+	opts.PageSelections["a.xlsx"] = "2"
+	active := app.activeRecipeOptions()
+	active.PageSelections["b.xlsx"] = "3"
+
+	got := app.currentBundleOptions([]string{"a.xlsx", "b.xlsx"}, nil)
+	if want := map[string]string{"a.xlsx": "1"}; !reflect.DeepEqual(got.PageSelections, want) {
+		t.Fatalf("page selections %v, want %v", got.PageSelections, want)
+	}
+	if got.OutputPath != "out.pdf" {
+		t.Fatalf("output %q, want out.pdf", got.OutputPath)
+	}
+
+	// A workspace without options clears them but keeps the recipe path
+	app.setRecipe("", nil)
+	if app.activeRecipeOptions() != nil || app.recipePath != "bundle.recipe.json" {
+		t.Fatalf("options %v, path %q after clearing", app.activeRecipeOptions(), app.recipePath)
+	}
+}
+
+func TestRecipeOptionsConcurrentAccess(t *testing.T) {
+	app := &App{lastConvertedSheets: make(map[string][]string)}
+	app.setRecipe("bundle.recipe.json", &BundleOptions{PageSelections: map[string]string{"a.xlsx": "1"}})
+
+	// Run with -race: bindings, the API and the file monitor use the options at the same time
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			app.currentBundleOptions([]string{"a.xlsx"}, nil)
+		}()
+		go func() {
+			defer wg.Done()
+			app.applyInputRename("a.xlsx", "b.xlsx")
+		}()
+		go func() {
+			defer wg.Done()
+			app.setRecipe("", &BundleOptions{PageSelections: map[string]string{"a.xlsx": "2"}})
+		}()
+	}
+	wg.Wait()
+}
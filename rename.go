@@ -0,0 +1,149 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// renameMatchWindow is how long a renamed input waits for the create event of its new name
+const renameMatchWindow = 2 * time.Second
+
+// eventFileRenamed is published when an input was renamed or moved
+const eventFileRenamed = "file:renamed"
+
+// FileRenamedEvent is the payload of "file:renamed"
+type FileRenamedEvent struct {
+	OldPath string `json:"oldPath"`
+	NewPath string `json:"newPath"`
+}
+
+// renameTracker pairs the rename event of an input with the create event of its new name.
+// A pair only matches when the new file is the same file: same inode or file ID,
+// or failing that the same size and modification time.
+type renameTracker struct {
+	mu      sync.Mutex
+	clock   clock
+	fs      fileStater
+	infos   map[string]os.FileInfo // Input -> stat taken at the last conversion
+	pending map[string]pendingRename
+}
+
+// pendingRename is an input that disappeared under a rename event
+type pendingRename struct {
+	info    os.FileInfo
+	expires time.Time
+}
+
+// newRenameTracker creates an empty tracker
+func newRenameTracker(clk clock, fs fileStater) *renameTracker {
+	return &renameTracker{
+		clock:   clk,
+		fs:      fs,
+		infos:   make(map[string]os.FileInfo),
+		pending: make(map[string]pendingRename),
+	}
+}
+
+// Record remembers the identity of the inputs
+func (t *renameTracker) Record(filePaths []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.infos = make(map[string]os.FileInfo)
+	for _, filePath := range filePaths {
+		if info, err := t.fs.Stat(filePath); err == nil {
+			t.infos[filepath.Clean(filePath)] = info
+		}
+	}
+}
+
+// Renamed notes that an input lost its name and may reappear under another
+func (t *renameTracker) Renamed(filePath string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	filePath = filepath.Clean(filePath)
+	if info, exists := t.infos[filePath]; exists {
+		t.pending[filePath] = pendingRename{info: info, expires: t.clock.Now().Add(renameMatchWindow)}
+	}
+}
+
+// Match returns the old path of a pending rename whose file now exists at newPath
+func (t *renameTracker) Match(newPath string) (string, bool) {
+	// Office saves through temporary files; only documents can be rename targets
+	name := filepath.Base(newPath)
+	if !isOfficeFile(strings.ToLower(filepath.Ext(name))) || isOfficeTempFile(name) {
+		return "", false
+	}
+
+	newInfo, err := t.fs.Stat(newPath)
+	if err != nil || newInfo.IsDir() {
+		return "", false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.clock.Now()
+	for oldPath, rename := range t.pending {
+		if now.After(rename.expires) {
+			delete(t.pending, oldPath)
+			continue
+		}
+		if !sameFileIdentity(rename.info, newInfo) {
+			continue
+		}
+		// A save that renames the original away and back is not a rename
+		if _, err := t.fs.Stat(oldPath); err == nil {
+			delete(t.pending, oldPath)
+			continue
+		}
+
+		delete(t.pending, oldPath)
+		t.infos[filepath.Clean(newPath)] = newInfo
+		delete(t.infos, oldPath)
+		return oldPath, true
+	}
+	return "", false
+}
+
+// sameFileIdentity reports whether two stats describe the same file
+func sameFileIdentity(oldInfo, newInfo os.FileInfo) bool {
+	if os.SameFile(oldInfo, newInfo) {
+		return true
+	}
+	// File IDs are not comparable everywhere, such as after a move across volumes
+	return oldInfo.Size() == newInfo.Size() && oldInfo.ModTime().Equal(newInfo.ModTime())
+}
+
+// applyInputRename carries the App state of a renamed input over to its new path
+func (a *App) applyInputRename(oldPath, newPath string) {
+	a.conversionMu.Lock()
+	defer a.conversionMu.Unlock()
+
+	for i, filePath := range a.lastConvertedFiles {
+		if filepath.Clean(filePath) == oldPath {
+			a.lastConvertedFiles[i] = newPath
+		}
+	}
+	if sheets, exists := a.lastConvertedSheets[oldPath]; exists {
+		a.lastConvertedSheets[newPath] = sheets
+		delete(a.lastConvertedSheets, oldPath)
+	}
+	if refs, exists := a.lastConvertedSheetRefs[oldPath]; exists {
+		a.lastConvertedSheetRefs[newPath] = refs
+		delete(a.lastConvertedSheetRefs, oldPath)
+	}
+
+	a.recipeMu.Lock()
+	defer a.recipeMu.Unlock()
+	if a.recipeOptions != nil {
+		if pages, exists := a.recipeOptions.PageSelections[oldPath]; exists {
+			a.recipeOptions.PageSelections[newPath] = pages
+			delete(a.recipeOptions.PageSelections, oldPath)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+// reportVersion is the current conversion report format version
+const reportVersion = 1
+
+// Report status values
+const (
+	reportCompleted = "completed" // Every input was converted
+	reportPartial   = "partial"   // Output was written but some inputs failed
+	reportFailed    = "failed"    // No output was written
+)
+
+// ConversionReport is a machine-readable summary of one conversion run
+type ConversionReport struct {
+	Version    int           `json:"version"`
+	StartedAt  time.Time     `json:"startedAt"`
+	DurationMs int64         `json:"durationMs"`
+	Status     string        `json:"status"` // "completed", "partial" or "failed"
+	Inputs     []InputReport `json:"inputs"`
+	Output     *OutputReport `json:"output,omitempty"`
+	Warnings   []string      `json:"warnings,omitempty"`
+	Errors     []string      `json:"errors,omitempty"`
+}
+
+// InputReport describes how one input file was processed
+type InputReport struct {
+	Path       string           `json:"path"`
+	Status     string           `json:"status"` // "converted" or "failed"
+	Backend    string           `json:"backend,omitempty"`
+	DurationMs int64            `json:"durationMs"`
+	CacheHit   bool             `json:"cacheHit"`
+	PageCount  int              `json:"pageCount"`
+	FirstPage  int              `json:"firstPage,omitempty"` // First page in the output PDF
+	LastPage   int              `json:"lastPage,omitempty"`  // Last page in the output PDF
+	Sheets     []string         `json:"sheets,omitempty"`    // Requested sheets
+	Pages      string           `json:"pages,omitempty"`     // Requested page selection
+	SheetPages []SheetPageRange `json:"sheetPages,omitempty"`
+	Warnings   []string         `json:"warnings,omitempty"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// SheetPageRange maps an Excel sheet to its pages in the output PDF
+type SheetPageRange struct {
+	Sheet     string `json:"sheet"`
+	FirstPage int    `json:"firstPage"`
+	LastPage  int    `json:"lastPage"`
+}
+
+// OutputReport describes the produced PDF
+type OutputReport struct {
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	SHA256    string `json:"sha256"`
+	PageCount int    `json:"pageCount"`
+}
+
+// newConversionReport starts a report for a run beginning now
+func newConversionReport() *ConversionReport {
+	return &ConversionReport{
+		Version:   reportVersion,
+		StartedAt: time.Now(),
+		Inputs:    []InputReport{},
+	}
+}
+
+// finish fills in the status, duration and output details
+func (r *ConversionReport) finish(outputPath string, err error) {
+	r.DurationMs = time.Since(r.StartedAt).Milliseconds()
+
+	failed := 0
+	for _, input := range r.Inputs {
+		if input.Status != "converted" {
+			failed++
+		}
+	}
+
+	switch {
+	case err != nil:
+		r.Status = reportFailed
+		r.Errors = append(r.Errors, err.Error())
+		return
+	case failed > 0:
+		r.Status = reportPartial
+	default:
+		r.Status = reportCompleted
+	}
+
+	output, outErr := describeOutput(outputPath)
+	if outErr != nil {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("failed to inspect output: %v", outErr))
+		return
+	}
+	r.Output = output
+}
+
+// describeOutput returns size, hash and page count of a PDF
+func describeOutput(outputPath string) (*OutputReport, error) {
+	hash, size, err := hashFile(outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	pageCount, err := api.PageCountFile(outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutputReport{
+		Path:      outputPath,
+		Size:      size,
+		SHA256:    hash,
+		PageCount: pageCount,
+	}, nil
+}
+
+// hashFile returns the hex SHA-256 and size of a file
+func hashFile(filePath string) (string, int64, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// WriteConversionReport writes a report as indented JSON
+func WriteConversionReport(reportPath string, report *ConversionReport) error {
+	if report == nil {
+		return fmt.Errorf("no conversion report available")
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversion report: %v", err)
+	}
+
+	if err := writeFileAtomic(reportPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write conversion report: %v", err)
+	}
+
+	return nil
+}
+
+// GetLastConversionReport returns the report of the most recent conversion run
+func (a *App) GetLastConversionReport() *ConversionReport {
+	return a.lastReport.Load()
+}
+
+// SaveLastConversionReport writes the report of the most recent conversion run to a file
+func (a *App) SaveLastConversionReport(reportPath string) error {
+	return WriteConversionReport(reportPath, a.lastReport.Load())
+}
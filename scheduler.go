@@ -0,0 +1,131 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// autoUpdateDebounce is how long a file must be quiet before it triggers a regeneration
+const autoUpdateDebounce = 500 * time.Millisecond
+
+// fileDebouncer groups bursts of change notifications per file.
+// fire is called once per file after it has been quiet for delay, with the last operation seen.
+type fileDebouncer struct {
+	mu      sync.Mutex
+	clock   clock
+	delay   time.Duration
+	pending map[string]*pendingChange
+	fire    func(filePath, operation string)
+}
+
+// pendingChange is a file waiting for its quiet period to end
+type pendingChange struct {
+	timer     clockTimer
+	operation string
+}
+
+// newFileDebouncer creates a debouncer calling fire after delay of quiet per file
+func newFileDebouncer(clk clock, delay time.Duration, fire func(filePath, operation string)) *fileDebouncer {
+	return &fileDebouncer{
+		clock:   clk,
+		delay:   delay,
+		pending: make(map[string]*pendingChange),
+		fire:    fire,
+	}
+}
+
+// Trigger records a change to filePath and restarts its quiet period
+func (d *fileDebouncer) Trigger(filePath, operation string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if change, exists := d.pending[filePath]; exists && change.timer.Stop() {
+		change.operation = operation
+		change.timer.Reset(d.delay)
+		return
+	}
+
+	// A timer that already fired gives up to the new one below
+	change := &pendingChange{operation: operation}
+	change.timer = d.clock.AfterFunc(d.delay, func() {
+		d.mu.Lock()
+		if d.pending[filePath] != change {
+			d.mu.Unlock()
+			return
+		}
+		operation := change.operation
+		delete(d.pending, filePath)
+		d.mu.Unlock()
+
+		d.fire(filePath, operation)
+	})
+	d.pending[filePath] = change
+}
+
+// Cancel drops the pending change of filePath and returns its last operation
+func (d *fileDebouncer) Cancel(filePath string) (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	change, exists := d.pending[filePath]
+	if !exists {
+		return "", false
+	}
+	change.timer.Stop()
+	delete(d.pending, filePath)
+	return change.operation, true
+}
+
+// Stop cancels all pending changes
+func (d *fileDebouncer) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for filePath, change := range d.pending {
+		change.timer.Stop()
+		delete(d.pending, filePath)
+	}
+}
+
+// regenScheduler runs regenerations one at a time.
+// Requests during a run are folded into exactly one follow-up run.
+type regenScheduler struct {
+	mu      sync.Mutex
+	running bool
+	pending bool
+	run     func()
+}
+
+// newRegenScheduler creates a scheduler for run
+func newRegenScheduler(run func()) *regenScheduler {
+	return &regenScheduler{run: run}
+}
+
+// Request starts a run, or schedules one follow-up run if one is in progress
+func (s *regenScheduler) Request() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		s.pending = true
+		return
+	}
+	s.running = true
+	go s.loop()
+}
+
+// loop runs until no more requests are pending
+func (s *regenScheduler) loop() {
+	for {
+		s.run()
+
+		s.mu.Lock()
+		if !s.pending {
+			s.running = false
+			s.mu.Unlock()
+			return
+		}
+		s.pending = false
+		s.mu.Unlock()
+	}
+}
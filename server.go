@@ -1,55 +1,138 @@
 package main
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
 	"net/http"
-	"os"
-	"path/filepath"
-	"strconv"
-	"time"
+	"net/url"
+	"strings"
 )
 
-// startHTTPServer starts a local HTTP server to serve PDF files
-func (a *App) startHTTPServer() {
-	// Find available port
-	for port := 8080; port < 8090; port++ {
-		mux := http.NewServeMux()
-
-		// Serve PDF files from cache directory
-		cacheDir := filepath.Join(os.TempDir(), "pdf-preview-go-cache")
-		mux.Handle("/pdf/", http.StripPrefix("/pdf/", http.FileServer(http.Dir(cacheDir))))
-
-		// Add CORS headers for WebView compatibility
-		corsHandler := func(h http.Handler) http.Handler {
-			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				w.Header().Set("Access-Control-Allow-Origin", "*")
-				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-				w.Header().Set("Access-Control-Allow-Headers", "*")
-				if r.Method == "OPTIONS" {
-					w.WriteHeader(http.StatusOK)
-					return
-				}
-				h.ServeHTTP(w, r)
-			})
+// serverTokenParam is the query parameter carrying the per-session token
+const serverTokenParam = "token"
+
+// allowedOrigins are the WebView origins that may call the server from script
+var allowedOrigins = map[string]bool{
+	"wails://wails":           true,
+	"wails://wails.localhost": true,
+	"http://wails.localhost":  true,
+}
+
+// startHTTPServer starts a loopback-only HTTP server to serve PDF files.
+// It listens on the port of the settings, or an ephemeral port if that is 0 or taken,
+// and requires a random per-session token.
+func (a *App) startHTTPServer() error {
+	listener, err := a.listenHTTP(a.currentSettings().HTTPPort)
+	if err != nil {
+		return fmt.Errorf("failed to start HTTP server: %v", err)
+	}
+
+	token, err := randomHex(32)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+
+	mux := http.NewServeMux()
+
+	// Serve registered outputs only, never the cache directory itself
+	mux.Handle("GET /pdf/", a.outputs)
+
+	// Versioned REST API for local tools
+	a.registerAPIRoutes(mux)
+
+	a.httpToken = token
+	a.httpPort = listener.Addr().(*net.TCPAddr).Port
+	a.httpServer = &http.Server{
+		Handler: a.originHandler(a.tokenHandler(mux)),
+	}
+
+	go func() {
+		if err := a.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("Warning: HTTP server stopped: %v\n", err)
 		}
+	}()
+
+	// Let local tools discover the API
+	if err := a.writeServerInfo(); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	return nil
+}
 
-		a.httpServer = &http.Server{
-			Addr:    ":" + strconv.Itoa(port),
-			Handler: corsHandler(mux),
+// serverURL returns an absolute URL on the local server including the session token
+func (a *App) serverURL(path string, query url.Values) string {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set(serverTokenParam, a.httpToken)
+	return fmt.Sprintf("http://127.0.0.1:%d%s?%s", a.httpPort, path, query.Encode())
+}
+
+// tokenHandler rejects requests that do not carry the session token
+func (a *App) tokenHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			// CORS preflight requests never carry credentials
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		token := r.URL.Query().Get(serverTokenParam)
+		if token == "" {
+			token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(a.httpToken)) != 1 {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
 		}
+		h.ServeHTTP(w, r)
+	})
+}
 
-		go func() {
-			if err := a.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-				// Port might be in use, try next one
+// originHandler allows cross-origin access from the WebView only
+func (a *App) originHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" {
+			if !allowedOrigins[origin] {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
 			}
-		}()
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, Range, Last-Event-ID")
+			w.Header().Set("Vary", "Origin")
+		}
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
 
-		// Test if server started successfully
-		time.Sleep(100 * time.Millisecond)
-		resp, err := http.Get("http://localhost:" + strconv.Itoa(port) + "/pdf/")
+// listenHTTP listens on port of the loopback interface, falling back to an ephemeral port
+func (a *App) listenHTTP(port int) (net.Listener, error) {
+	if port != 0 {
+		listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
 		if err == nil {
-			resp.Body.Close()
-			a.httpPort = port
-			return
+			return listener, nil
 		}
+		fmt.Printf("Warning: port %d is not available, using a free port: %v\n", port, err)
+	}
+	return net.Listen("tcp", "127.0.0.1:0")
+}
+
+// randomHex returns n random bytes as a hex string, for tokens and opaque IDs
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random ID: %v", err)
 	}
+	return hex.EncodeToString(b), nil
 }
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,27 +8,20 @@ import (
 	"time"
 )
 
+// directoryHistoryName is the state file of the directory history
+const directoryHistoryName = "directory_history.json"
+
 // GetDirectoryHistory returns the list of recently used directories
 func (a *App) GetDirectoryHistory() ([]DirectoryHistory, error) {
-	cacheFilePath := filepath.Join(os.TempDir(), "pdf-preview-go-cache", "directory_history.json")
-
-	// Check if cache file exists
-	if _, err := os.Stat(cacheFilePath); os.IsNotExist(err) {
-		return []DirectoryHistory{}, nil
-	}
-
-	// Read cache file
-	data, err := os.ReadFile(cacheFilePath)
-	if err != nil {
-		return []DirectoryHistory{}, nil
-	}
-
-	// Parse JSON
 	var history []DirectoryHistory
-	if err := json.Unmarshal(data, &history); err != nil {
+	if exists, err := a.state.Load(directoryHistoryName, &history); err != nil || !exists {
 		return []DirectoryHistory{}, nil
 	}
+	return recentDirectories(history), nil
+}
 
+// recentDirectories keeps the existing directories of history, most recent first
+func recentDirectories(history []DirectoryHistory) []DirectoryHistory {
 	// Filter out directories that no longer exist and sort by last used
 	validHistory := []DirectoryHistory{}
 	for _, dir := range history {
@@ -52,7 +44,7 @@ func (a *App) GetDirectoryHistory() ([]DirectoryHistory, error) {
 		validHistory = validHistory[:20]
 	}
 
-	return validHistory, nil
+	return validHistory
 }
 
 // AddDirectoryToHistory adds a directory to the usage history
@@ -67,12 +59,27 @@ func (a *App) AddDirectoryToHistory(dirPath string) error {
 		absPath = dirPath
 	}
 
-	// Get current history
-	history, err := a.GetDirectoryHistory()
+	// Get display name (folder name)
+	displayName := filepath.Base(absPath)
+	if displayName == "." || displayName == "" {
+		displayName = absPath
+	}
+
+	// Update the history without another instance writing in between
+	var history []DirectoryHistory
+	err = a.state.Update(directoryHistoryName, &history, func(bool) error {
+		history = addDirectoryEntry(recentDirectories(history), absPath, displayName)
+		return nil
+	})
 	if err != nil {
-		history = []DirectoryHistory{}
+		return fmt.Errorf("failed to write directory history: %v", err)
 	}
 
+	return nil
+}
+
+// addDirectoryEntry records a use of absPath in history
+func addDirectoryEntry(history []DirectoryHistory, absPath, displayName string) []DirectoryHistory {
 	// Check if directory already exists in history
 	var existingDir *DirectoryHistory
 	for i := range history {
@@ -82,12 +89,6 @@ func (a *App) AddDirectoryToHistory(dirPath string) error {
 		}
 	}
 
-	// Get display name (folder name)
-	displayName := filepath.Base(absPath)
-	if displayName == "." || displayName == "" {
-		displayName = absPath
-	}
-
 	if existingDir != nil {
 		// Update existing entry
 		existingDir.LastUsed = time.Now()
@@ -109,20 +110,7 @@ func (a *App) AddDirectoryToHistory(dirPath string) error {
 		history = history[:20]
 	}
 
-	// Save updated history
-	cacheFilePath := filepath.Join(os.TempDir(), "pdf-preview-go-cache", "directory_history.json")
-	os.MkdirAll(filepath.Dir(cacheFilePath), 0755)
-
-	data, err := json.MarshalIndent(history, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal directory history: %v", err)
-	}
-
-	if err := os.WriteFile(cacheFilePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write directory history: %v", err)
-	}
-
-	return nil
+	return history
 }
 
 // SaveDirectorySessionCache saves the complete session state for a directory
@@ -138,10 +126,7 @@ func (a *App) SaveDirectorySessionCache(dirPath string, selectedFiles []string,
 	}
 
 	dirHash := a.createDirectoryHash(absPath)
-	cacheFilePath := filepath.Join(os.TempDir(), "pdf-preview-go-cache", fmt.Sprintf("session_%s.json", dirHash))
-
-	// Create cache directory if it doesn't exist
-	os.MkdirAll(filepath.Dir(cacheFilePath), 0755)
+	workspaceID, sessionName := a.activeWorkspaceSession(dirHash, absPath)
 
 	// Calculate file hashes for validation
 	fileHashes := make(map[string]string)
@@ -170,22 +155,30 @@ func (a *App) SaveDirectorySessionCache(dirPath string, selectedFiles []string,
 		CurrentFile:     currentFile,
 		SheetSelections: sheetSelections,
 		FileHashes:      fileHashes,
-		ExpiryTime:      time.Now().AddDate(0, 3, 0), // Expire after 3 months
+		ExpiryTime:      time.Now().Add(retention(a.currentSettings().SessionDays)),
+		Workspace:       workspaceID,
+		SheetRefs:       sheetRefsByFile(sheetSelections),
 	}
 
-	// Write to JSON file
-	data, err := json.MarshalIndent(cache, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal session cache: %v", err)
+	// Output options belong to the workspace of the working directory
+	if recipe := a.activeRecipeOptions(); recipe != nil && a.isWorkingDirectory(absPath) {
+		cache.PageSelections = recipe.PageSelections
+		cache.PostProcess = &recipe.PostProcess
+		cache.OutputPath = recipe.OutputPath
 	}
 
-	if err := os.WriteFile(cacheFilePath, data, 0644); err != nil {
+	if err := a.state.Save(sessionName, cache); err != nil {
 		return fmt.Errorf("failed to write session cache: %v", err)
 	}
 
 	return nil
 }
 
+// sessionStateName is the state file of the session of the default workspace of a directory
+func sessionStateName(dirHash string) string {
+	return fmt.Sprintf("session_%s.json", dirHash)
+}
+
 // LoadDirectorySessionCache loads the complete session state for a directory
 func (a *App) LoadDirectorySessionCache(dirPath string) (*DirectorySessionCache, error) {
 	if dirPath == "" {
@@ -199,45 +192,29 @@ func (a *App) LoadDirectorySessionCache(dirPath string) (*DirectorySessionCache,
 	}
 
 	dirHash := a.createDirectoryHash(absPath)
-	cacheFilePath := filepath.Join(os.TempDir(), "pdf-preview-go-cache", fmt.Sprintf("session_%s.json", dirHash))
+	_, sessionName := a.activeWorkspaceSession(dirHash, absPath)
 
-	// Check if cache file exists
-	if _, err := os.Stat(cacheFilePath); os.IsNotExist(err) {
-		return nil, nil // No cache found
-	}
-
-	// Read cache file
-	data, err := os.ReadFile(cacheFilePath)
+	var cache DirectorySessionCache
+	exists, err := a.state.Load(sessionName, &cache)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read session cache: %v", err)
 	}
-
-	// Parse JSON
-	var cache DirectorySessionCache
-	if err := json.Unmarshal(data, &cache); err != nil {
-		return nil, fmt.Errorf("failed to parse session cache: %v", err)
+	if !exists {
+		return nil, nil // No cache found
 	}
 
-	// Check if cache is expired
-	if time.Now().After(cache.ExpiryTime) {
+	// Check if cache is expired; sessions of listed workspaces expire with their directory
+	if time.Now().After(cache.ExpiryTime) && !a.hasWorkspaceList(dirHash) {
 		// Remove expired cache file
-		os.Remove(cacheFilePath)
+		a.state.Remove(sessionName)
 		return nil, nil
 	}
 
-	// Validate selected files exist and haven't changed
+	// Validate selected files still exist; edited files stay selected
 	validSelectedFiles := []string{}
 	for _, filePath := range cache.SelectedFiles {
 		if _, err := os.Stat(filePath); err == nil {
-			// Check file hash if available
-			if expectedHash, exists := cache.FileHashes[filePath]; exists {
-				if currentHash, err := a.calculateFileHash(filePath); err == nil && currentHash == expectedHash {
-					validSelectedFiles = append(validSelectedFiles, filePath)
-				}
-			} else {
-				// No hash available, assume file is valid
-				validSelectedFiles = append(validSelectedFiles, filePath)
-			}
+			validSelectedFiles = append(validSelectedFiles, filePath)
 		}
 	}
 	cache.SelectedFiles = validSelectedFiles
@@ -258,77 +235,97 @@ func (a *App) LoadDirectorySessionCache(dirPath string) (*DirectorySessionCache,
 		}
 	}
 
-	// Validate sheet selections
-	validSheetSelections := make(map[string][]string)
-	for filePath, sheets := range cache.SheetSelections {
-		if _, err := os.Stat(filePath); err == nil {
-			// Check file hash if available
-			if expectedHash, exists := cache.FileHashes[filePath]; exists {
-				if currentHash, err := a.calculateFileHash(filePath); err == nil && currentHash == expectedHash {
-					validSheetSelections[filePath] = sheets
-				}
-			} else {
-				// No hash available, assume sheets are valid
-				validSheetSelections[filePath] = sheets
-			}
-		}
-	}
-	cache.SheetSelections = validSheetSelections
+	// Reapply sheet selections to workbooks edited since, following renamed sheets
+	cache.SheetSelections, cache.SheetIssues = a.validSheetSelections(cache.SheetSelections, cache.SheetRefs, cache.FileHashes)
 
 	return &cache, nil
 }
 
-// CleanupDirectorySessionCache removes old session cache files
+// CleanupDirectorySessionCache removes old session cache files.
+// A directory with a workspace list expires as a whole: the list and the sessions of all its workspaces
+// are kept until none of them was used within maxAge, and are then removed together.
 func (a *App) CleanupDirectorySessionCache(maxAge time.Duration) error {
-	cacheDir := filepath.Join(os.TempDir(), "pdf-preview-go-cache")
-	entries, err := os.ReadDir(cacheDir)
+	sessions, err := a.state.List("session_")
+	if err != nil {
+		return err
+	}
+	indexes, err := a.state.List("workspaces_")
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil // Cache directory doesn't exist yet
-		}
 		return err
 	}
 
-	cutoff := time.Now().Add(-maxAge)
+	now := time.Now()
+	cutoff := now.Add(-maxAge)
 	cleaned := 0
+	removed := make(map[string]bool)
+	remove := func(name string) {
+		if err := a.state.Remove(name); err == nil {
+			cleaned++
+		}
+		removed[name] = true
+	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
+	// A directory was last used when its list or any of its sessions was last saved
+	lastUsed := make(map[string]time.Time)
+	for _, entry := range sessions {
+		if dirHash, _, ok := parseSessionStateName(entry.Name); ok && entry.ModTime.After(lastUsed[dirHash]) {
+			lastUsed[dirHash] = entry.ModTime
+		}
+	}
+
+	listed := make(map[string]bool)  // Sessions of the workspaces of kept lists
+	managed := make(map[string]bool) // Directories whose sessions belong to a workspace list
+	for _, entry := range indexes {
+		dirHash := strings.TrimSuffix(strings.TrimPrefix(entry.Name, "workspaces_"), ".json")
+		var idx workspaceIndex
+		if exists, err := a.state.Load(entry.Name, &idx); err != nil || !exists {
+			continue // Leave unreadable lists and their sessions alone
 		}
+		managed[dirHash] = true
 
-		// Only process session cache files
-		if !strings.HasPrefix(entry.Name(), "session_") || !strings.HasSuffix(entry.Name(), ".json") {
+		if used := lastUsed[dirHash]; entry.ModTime.Before(cutoff) && used.Before(cutoff) {
+			remove(entry.Name)
+			for _, session := range sessions {
+				if sessionHash, _, ok := parseSessionStateName(session.Name); ok && sessionHash == dirHash {
+					remove(session.Name)
+				}
+			}
 			continue
 		}
+		for _, ws := range idx.Workspaces {
+			listed[workspaceSessionName(dirHash, ws.ID)] = true
+		}
+	}
 
-		filePath := filepath.Join(cacheDir, entry.Name())
+	for _, entry := range sessions {
+		dirHash, _, ok := parseSessionStateName(entry.Name)
+		if !ok || removed[entry.Name] {
+			continue
+		}
 
-		// Check file modification time
-		info, err := entry.Info()
-		if err != nil {
+		if managed[dirHash] {
+			// Sessions of deleted workspaces; a new one is written shortly before it is listed
+			if !listed[entry.Name] && entry.ModTime.Before(now.Add(-time.Hour)) {
+				remove(entry.Name)
+			}
 			continue
 		}
 
 		// Also check cache content for expiry
-		shouldRemove := info.ModTime().Before(cutoff)
+		shouldRemove := entry.ModTime.Before(cutoff)
 
 		if !shouldRemove {
 			// Check cache file content for expiry
-			if data, err := os.ReadFile(filePath); err == nil {
-				var cache DirectorySessionCache
-				if json.Unmarshal(data, &cache) == nil {
-					if time.Now().After(cache.ExpiryTime) {
-						shouldRemove = true
-					}
+			var cache DirectorySessionCache
+			if exists, err := a.state.Load(entry.Name, &cache); err == nil && exists {
+				if now.After(cache.ExpiryTime) {
+					shouldRemove = true
 				}
 			}
 		}
 
 		if shouldRemove {
-			if err := os.Remove(filePath); err == nil {
-				cleaned++
-			}
+			remove(entry.Name)
 		}
 	}
 
@@ -338,3 +335,20 @@ func (a *App) CleanupDirectorySessionCache(maxAge time.Duration) error {
 
 	return nil
 }
+
+// parseSessionStateName returns the directory hash and workspace ID of a session state file
+func parseSessionStateName(name string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(name, "session_")
+	if !ok {
+		return "", "", false
+	}
+	rest, ok = strings.CutSuffix(rest, ".json")
+	if !ok || rest == "" {
+		return "", "", false
+	}
+	dirHash, id, named := strings.Cut(rest, "_")
+	if !named {
+		id = defaultWorkspaceID
+	}
+	return dirHash, id, true
+}
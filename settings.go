@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// eventSettingsChanged is published with the new settings after they change
+const eventSettingsChanged = "settings:changed"
+
+// eventSettingsOpenRequest asks the frontend to show the settings
+const eventSettingsOpenRequest = "settings:open-requested"
+
+// settingsName is the state file of the settings
+const settingsName = "settings.json"
+
+// supportedLanguages are the languages of the user interface; messages has a catalog for each
+var supportedLanguages = []string{"ja", "en"}
+
+// Settings are the user preferences. Subsystems read them when they need a value,
+// so a change takes effect without a restart unless noted.
+type Settings struct {
+	AutoUpdate         bool   `json:"autoUpdate"`         // Regenerate the preview when an input changes
+	PollingIntervalMs  int    `json:"pollingIntervalMs"`  // How often inputs on network shares are checked
+	SheetSelectionDays int    `json:"sheetSelectionDays"` // How long saved sheet selections are kept
+	SessionDays        int    `json:"sessionDays"`        // How long directory sessions are kept
+	PDFCacheDays       int    `json:"pdfCacheDays"`       // How long converted PDFs are cached
+	TreeDepth          int    `json:"treeDepth"`          // Directory levels listed in the file tree
+	HTTPPort           int    `json:"httpPort"`           // Port of the local HTTP server; 0 picks a free one. Applies on restart
+	Language           string `json:"language"`           // Language of the menus, dialogs, window content and LAN share pages
+}
+
+// defaultSettings returns the settings used until the user changes them
+func defaultSettings() Settings {
+	return Settings{
+		AutoUpdate:         true,
+		PollingIntervalMs:  int(pollingInterval / time.Millisecond),
+		SheetSelectionDays: 30,
+		SessionDays:        90,
+		PDFCacheDays:       30,
+		TreeDepth:          3,
+		HTTPPort:           0,
+		Language:           "ja",
+	}
+}
+
+// settingCheck validates one field and restores its default
+type settingCheck struct {
+	problem string
+	valid   func(s *Settings) bool
+	reset   func(s *Settings, defaults Settings)
+}
+
+// settingChecks validate Settings; a loaded file keeps its valid fields
+var settingChecks = []settingCheck{
+	{
+		problem: fmt.Sprintf("polling interval must be between %d and %d ms", minPollingInterval/time.Millisecond, maxPollingInterval/time.Millisecond),
+		valid: func(s *Settings) bool {
+			interval := time.Duration(s.PollingIntervalMs) * time.Millisecond
+			return interval >= minPollingInterval && interval <= maxPollingInterval
+		},
+		reset: func(s *Settings, d Settings) { s.PollingIntervalMs = d.PollingIntervalMs },
+	},
+	{
+		problem: "sheet selection retention must be between 1 and 3650 days",
+		valid:   func(s *Settings) bool { return s.SheetSelectionDays >= 1 && s.SheetSelectionDays <= 3650 },
+		reset:   func(s *Settings, d Settings) { s.SheetSelectionDays = d.SheetSelectionDays },
+	},
+	{
+		problem: "session retention must be between 1 and 3650 days",
+		valid:   func(s *Settings) bool { return s.SessionDays >= 1 && s.SessionDays <= 3650 },
+		reset:   func(s *Settings, d Settings) { s.SessionDays = d.SessionDays },
+	},
+	{
+		problem: "PDF cache retention must be between 1 and 3650 days",
+		valid:   func(s *Settings) bool { return s.PDFCacheDays >= 1 && s.PDFCacheDays <= 3650 },
+		reset:   func(s *Settings, d Settings) { s.PDFCacheDays = d.PDFCacheDays },
+	},
+	{
+		problem: "tree depth must be between 1 and 10",
+		valid:   func(s *Settings) bool { return s.TreeDepth >= 1 && s.TreeDepth <= 10 },
+		reset:   func(s *Settings, d Settings) { s.TreeDepth = d.TreeDepth },
+	},
+	{
+		problem: "HTTP port must be 0 or between 1024 and 65535",
+		valid:   func(s *Settings) bool { return s.HTTPPort == 0 || (s.HTTPPort >= 1024 && s.HTTPPort <= 65535) },
+		reset:   func(s *Settings, d Settings) { s.HTTPPort = d.HTTPPort },
+	},
+	{
+		problem: fmt.Sprintf("language must be one of %s", strings.Join(supportedLanguages, ", ")),
+		valid:   func(s *Settings) bool { return slices.Contains(supportedLanguages, s.Language) },
+		reset:   func(s *Settings, d Settings) { s.Language = d.Language },
+	},
+}
+
+// validate reports every invalid field of s
+func (s Settings) validate() error {
+	var problems []string
+	for _, check := range settingChecks {
+		if !check.valid(&s) {
+			problems = append(problems, check.problem)
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid settings: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// loadSettings reads the stored settings over the defaults; invalid fields keep their defaults
+func loadSettings(store *stateStore) Settings {
+	defaults := defaultSettings()
+	settings := defaults
+	if _, err := store.Load(settingsName, &settings); err != nil {
+		fmt.Printf("Warning: failed to read settings: %v\n", err)
+		return defaults
+	}
+
+	for _, check := range settingChecks {
+		if !check.valid(&settings) {
+			fmt.Printf("Warning: %s; using the default\n", check.problem)
+			check.reset(&settings, defaults)
+		}
+	}
+	return settings
+}
+
+// currentSettings returns a copy of the settings
+func (a *App) currentSettings() Settings {
+	a.settingsMu.RLock()
+	defer a.settingsMu.RUnlock()
+	return a.settings
+}
+
+// GetSettings returns the current settings
+func (a *App) GetSettings() Settings {
+	return a.currentSettings()
+}
+
+// UpdateSettings validates, stores and applies new settings, and returns them
+func (a *App) UpdateSettings(settings Settings) (Settings, error) {
+	return a.changeSettings(func(s *Settings) { *s = settings })
+}
+
+// ResetSettings restores the default settings
+func (a *App) ResetSettings() (Settings, error) {
+	return a.UpdateSettings(defaultSettings())
+}
+
+// changeSettings applies change to a copy of the settings, then validates, stores and applies it
+func (a *App) changeSettings(change func(s *Settings)) (Settings, error) {
+	a.settingsMu.Lock()
+	previous := a.settings
+	settings := previous
+	change(&settings)
+	if err := settings.validate(); err != nil {
+		a.settingsMu.Unlock()
+		return previous, err
+	}
+	if err := a.state.Save(settingsName, settings); err != nil {
+		a.settingsMu.Unlock()
+		return previous, fmt.Errorf("failed to write settings: %v", err)
+	}
+	a.settings = settings
+	a.settingsMu.Unlock()
+
+	a.applySettings(previous, settings)
+	a.emit(eventSettingsChanged, settings)
+	return settings, nil
+}
+
+// applySettings passes changed settings to the subsystems that keep their own copy
+func (a *App) applySettings(previous, settings Settings) {
+	if a.monitor != nil {
+		a.monitor.SetAutoUpdate(settings.AutoUpdate)
+		a.monitor.SetPollingInterval(time.Duration(settings.PollingIntervalMs) * time.Millisecond)
+	}
+	if settings.TreeDepth != previous.TreeDepth && a.treeWatcher != nil {
+		a.watchTree(a.initialDir)
+	}
+	if settings.Language != previous.Language && a.ctx != nil {
+		runtime.MenuSetApplicationMenu(a.ctx, a.buildMenu())
+	}
+}
+
+// retention converts a number of days to a duration
+func retention(days int) time.Duration {
+	return time.Duration(days) * 24 * time.Hour
+}
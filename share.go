@@ -0,0 +1,445 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math/big"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Share mode settings
+const (
+	shareCodeLength       = 8
+	shareCodeAlphabet     = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // No 0/O or 1/I
+	shareCookieName       = "pdf_preview_share"
+	shareMaxLoginFailures = 10               // Wrong codes an address may enter before it is locked out
+	shareLockout          = 15 * time.Minute // How long a locked out address has to wait
+)
+
+// eventShareChanged is published when sharing starts or stops
+const eventShareChanged = "share:changed"
+
+// ShareInfo describes the current LAN share
+type ShareInfo struct {
+	Active bool     `json:"active"`
+	Code   string   `json:"code,omitempty"` // Access code for the next reviewer; each code opens one browser
+	Port   int      `json:"port,omitempty"`
+	URLs   []string `json:"urls,omitempty"` // Viewer URL on each LAN address
+}
+
+// shareServer serves a read-only viewer of the current preview to the LAN.
+// It has its own listener and routes, so neither the API nor the cache directory is reachable.
+type shareServer struct {
+	app      *App
+	server   *http.Server
+	port     int
+	code     string
+	mu       sync.Mutex
+	sessions map[string]bool
+	failures map[string]*loginFailures // Remote address -> wrong codes entered
+}
+
+// loginFailures counts the wrong codes entered from one address
+type loginFailures struct {
+	count       int
+	lockedUntil time.Time
+}
+
+// StartSharing starts the LAN share server, or returns the running one
+func (a *App) StartSharing() (ShareInfo, error) {
+	a.shareMu.Lock()
+	defer a.shareMu.Unlock()
+
+	if a.share != nil {
+		return a.share.info(), nil
+	}
+
+	code, err := newShareCode()
+	if err != nil {
+		return ShareInfo{}, err
+	}
+
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return ShareInfo{}, fmt.Errorf("failed to start share server: %v", err)
+	}
+
+	s := &shareServer{
+		app:      a,
+		port:     listener.Addr().(*net.TCPAddr).Port,
+		code:     code,
+		sessions: make(map[string]bool),
+		failures: make(map[string]*loginFailures),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleViewer)
+	mux.HandleFunc("POST /login", s.handleLogin)
+	mux.HandleFunc("GET /output.pdf", s.requireSession(s.handleOutput))
+	mux.HandleFunc("GET /events", s.requireSession(s.handleEvents))
+	s.server = &http.Server{Handler: mux}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("Warning: share server stopped: %v\n", err)
+		}
+	}()
+
+	a.share = s
+	info := s.info()
+	a.emit(eventShareChanged, info)
+	return info, nil
+}
+
+// StopSharing stops the LAN share server and disconnects all reviewers
+func (a *App) StopSharing() error {
+	a.shareMu.Lock()
+	defer a.shareMu.Unlock()
+
+	if a.share == nil {
+		return nil
+	}
+	err := a.share.server.Close()
+	a.share = nil
+	a.emit(eventShareChanged, ShareInfo{})
+	return err
+}
+
+// GetShareStatus returns the current LAN share
+func (a *App) GetShareStatus() ShareInfo {
+	a.shareMu.Lock()
+	defer a.shareMu.Unlock()
+
+	if a.share == nil {
+		return ShareInfo{}
+	}
+	return a.share.info()
+}
+
+// info describes the share with a viewer URL for every LAN address
+func (s *shareServer) info() ShareInfo {
+	s.mu.Lock()
+	info := ShareInfo{Active: true, Code: s.code, Port: s.port}
+	s.mu.Unlock()
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return info
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() || ipNet.IP.To4() == nil {
+			continue
+		}
+		info.URLs = append(info.URLs, fmt.Sprintf("http://%s:%d/", ipNet.IP, s.port))
+	}
+	return info
+}
+
+// hasSession reports whether the request carries a session cookie issued by handleLogin
+func (s *shareServer) hasSession(r *http.Request) bool {
+	cookie, err := r.Cookie(shareCookieName)
+	if err != nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[cookie.Value]
+}
+
+// requireSession rejects requests from browsers that have not entered the access code
+func (s *shareServer) requireSession(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.hasSession(r) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// handleViewer shows the viewer, or the access code form to browsers without a session
+func (s *shareServer) handleViewer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; style-src 'unsafe-inline'")
+
+	page := sharePage{app: s.app}
+	if !s.hasSession(r) {
+		page.Failed = r.URL.Query().Has("failed")
+		shareLoginPage.Execute(w, page)
+		return
+	}
+	shareViewerPage.Execute(w, page)
+}
+
+// handleLogin exchanges the access code for a session cookie.
+// A code works once: it is replaced as soon as it is used, and the presenter reads the next one
+// to the next reviewer. Wrong codes lock out only the address that entered them.
+func (s *shareServer) handleLogin(w http.ResponseWriter, r *http.Request) {
+	code := strings.ToUpper(strings.TrimSpace(r.FormValue("code")))
+	addr, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		addr = r.RemoteAddr
+	}
+	now := time.Now()
+
+	s.mu.Lock()
+	s.pruneFailuresLocked(now)
+	failures := s.failures[addr]
+	if failures != nil && now.Before(failures.lockedUntil) {
+		s.mu.Unlock()
+		http.Error(w, "too many attempts; try again later", http.StatusTooManyRequests)
+		return
+	}
+	if s.code == "" || subtle.ConstantTimeCompare([]byte(code), []byte(s.code)) != 1 {
+		if failures == nil {
+			failures = &loginFailures{}
+			s.failures[addr] = failures
+		}
+		failures.count++
+		if failures.count >= shareMaxLoginFailures {
+			failures.count = 0
+			failures.lockedUntil = now.Add(shareLockout)
+		}
+		s.mu.Unlock()
+		http.Redirect(w, r, "/?failed", http.StatusSeeOther)
+		return
+	}
+
+	// Replace the used code; without a new one nobody else can log in until sharing restarts
+	next, err := newShareCode()
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		next = ""
+	}
+	s.code = next
+	delete(s.failures, addr)
+	s.mu.Unlock()
+	s.app.shareCodeChanged(s)
+
+	session, err := randomHex(32)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.mu.Lock()
+	s.sessions[session] = true
+	s.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     shareCookieName,
+		Value:    session,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// pruneFailuresLocked forgets addresses whose lockout has passed; the caller must hold s.mu
+func (s *shareServer) pruneFailuresLocked(now time.Time) {
+	for addr, failures := range s.failures {
+		if !failures.lockedUntil.IsZero() && now.After(failures.lockedUntil) {
+			delete(s.failures, addr)
+		}
+	}
+}
+
+// shareCodeChanged shows the next access code of a running share to the presenter
+func (a *App) shareCodeChanged(s *shareServer) {
+	a.shareMu.Lock()
+	defer a.shareMu.Unlock()
+
+	if a.share == s {
+		a.emit(eventShareChanged, s.info())
+	}
+}
+
+// handleOutput serves the current preview PDF
+func (s *shareServer) handleOutput(w http.ResponseWriter, r *http.Request) {
+	entry, exists := s.app.outputs.Current(previewSlot)
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+	servePDF(w, r, entry.path, entry.sha256)
+}
+
+// shareOutputEvent is sent to reviewers when the preview changes; it carries no file paths
+type shareOutputEvent struct {
+	ETag string `json:"etag"`
+}
+
+// handleEvents streams an "output" event whenever the preview PDF changes
+func (s *shareServer) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// A new viewer already shows the current PDF; only reconnects replay what they missed
+	lastID := r.Header.Get("Last-Event-ID")
+	after, _ := strconv.ParseUint(lastID, 10, 64)
+	missed, events, cancel := s.app.events.Subscribe(after)
+	defer cancel()
+	if lastID == "" {
+		missed = nil
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event AppEvent) error {
+		status, ok := event.Data.(ConversionStatus)
+		if !ok || status.Status != "completed" || status.Unchanged {
+			return nil
+		}
+		entry, exists := s.app.outputs.Current(previewSlot)
+		if !exists {
+			return nil
+		}
+		data, err := json.Marshal(shareOutputEvent{ETag: entry.sha256})
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "id: %d\nevent: output\ndata: %s\n\n", event.ID, data)
+		return err
+	}
+
+	for _, event := range missed {
+		if err := send(event); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := send(event); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// newShareCode returns a random access code that is easy to read aloud
+func newShareCode() (string, error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(shareCodeAlphabet)))
+	for i := 0; i < shareCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate access code: %v", err)
+		}
+		b.WriteByte(shareCodeAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// sharePage fills the share pages in the language of the settings
+type sharePage struct {
+	app    *App
+	Failed bool // A wrong access code was entered
+}
+
+// Lang is the language of the page
+func (p sharePage) Lang() string {
+	return p.app.currentSettings().Language
+}
+
+// T returns the string key of the message catalog
+func (p sharePage) T(key string) string {
+	return p.app.text(key)
+}
+
+// shareLoginPage asks for the access code
+var shareLoginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.T "sharePage.title"}}</title>
+<style>
+  body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; display: flex; align-items: center; justify-content: center; height: 100vh; margin: 0; background: #f8f9fa; }
+  form { background: white; padding: 2rem; border: 1px solid #dee2e6; border-radius: 8px; text-align: center; }
+  input { font-size: 1.5rem; letter-spacing: 0.2em; text-transform: uppercase; width: 10em; text-align: center; }
+  button { margin-top: 1rem; padding: 0.5rem 1.5rem; }
+  .error { color: #dc3545; }
+</style>
+</head>
+<body>
+<form method="post" action="/login">
+  <h3>{{.T "sharePage.title"}}</h3>
+  <p>{{.T "sharePage.prompt"}}</p>
+  {{if .Failed}}<p class="error">{{.T "sharePage.wrongCode"}}</p>{{end}}
+  <input name="code" autocomplete="off" autofocus required>
+  <div><button type="submit">{{.T "sharePage.view"}}</button></div>
+</form>
+</body>
+</html>
+`))
+
+// shareViewerPage shows the current PDF and reloads it when the preview changes
+var shareViewerPage = template.Must(template.New("viewer").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.T "sharePage.title"}}</title>
+<style>
+  html, body { margin: 0; height: 100%; }
+  body { display: flex; flex-direction: column; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; }
+  header { padding: 0.25rem 1rem; background: #f8f9fa; border-bottom: 1px solid #dee2e6; font-size: 12px; color: #495057; }
+  iframe { flex: 1; border: none; width: 100%; }
+</style>
+</head>
+<body>
+<header id="status">{{.T "sharePage.readOnly"}}</header>
+<iframe id="viewer" src="/output.pdf"></iframe>
+<script>
+  const viewer = document.getElementById('viewer')
+  const status = document.getElementById('status')
+  const title = {{.T "sharePage.readOnly"}}
+  const events = new EventSource('/events')
+  events.addEventListener('output', () => {
+    viewer.contentWindow.location.reload()
+    status.textContent = title + ' - ' + {{.T "sharePage.updated"}} + ': ' + new Date().toLocaleTimeString()
+  })
+  events.onopen = () => {
+    status.textContent = title
+  }
+  events.onerror = () => {
+    status.textContent = title + ' - ' + {{.T "sharePage.disconnected"}}
+  }
+</script>
+</body>
+</html>
+`))
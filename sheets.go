@@ -0,0 +1,185 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// eventSheetSelectionChanged is published when auto-update follows renamed or deleted sheets
+const eventSheetSelectionChanged = "sheets:selection-changed"
+
+// Statuses of a SheetSelectionIssue
+const (
+	sheetRenamed = "renamed" // The sheet has a new name; the selection follows it
+	sheetMissing = "missing" // The sheet was deleted; it is no longer selected
+)
+
+// SheetRef identifies a selected sheet by its name and, for xlsx workbooks, by its sheetId,
+// which Excel keeps when a sheet is renamed or moved
+type SheetRef struct {
+	Name    string `json:"name"`
+	SheetID int    `json:"sheetId,omitempty"`
+}
+
+// SheetSelectionIssue is a selected sheet that was renamed or deleted after it was selected
+type SheetSelectionIssue struct {
+	Sheet   string `json:"sheet"`             // Name when the sheet was selected
+	Status  string `json:"status"`            // "renamed" or "missing"
+	NewName string `json:"newName,omitempty"` // Current name of a renamed sheet
+}
+
+// SheetSelectionChangedEvent is the payload of "sheets:selection-changed"
+type SheetSelectionChangedEvent struct {
+	File   string                `json:"file"`
+	Sheets []string              `json:"sheets"` // Selection after following the changes
+	Issues []SheetSelectionIssue `json:"issues"`
+}
+
+// workbookSheet is a sheet listed in xl/workbook.xml
+type workbookSheet struct {
+	Name    string `xml:"name,attr"`
+	SheetID int    `xml:"sheetId,attr"`
+	State   string `xml:"state,attr"` // "hidden" or "veryHidden"; empty when visible
+}
+
+// readWorkbookSheets lists the sheets of an xlsx or xlsm workbook in tab order
+func readWorkbookSheets(filePath string) ([]workbookSheet, error) {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	if ext != ".xlsx" && ext != ".xlsm" {
+		return nil, fmt.Errorf("sheet IDs are not available for %s files", ext)
+	}
+
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", filepath.Base(filePath), err)
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		if file.Name != "xl/workbook.xml" {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+
+		var workbook struct {
+			Sheets []workbookSheet `xml:"sheets>sheet"`
+		}
+		if err := xml.NewDecoder(rc).Decode(&workbook); err != nil {
+			return nil, fmt.Errorf("failed to read workbook.xml: %v", err)
+		}
+		return workbook.Sheets, nil
+	}
+	return nil, fmt.Errorf("workbook.xml not found in %s", filepath.Base(filePath))
+}
+
+// sheetRefs resolves selected sheet names to references; IDs are left out if the workbook cannot be read
+func sheetRefs(filePath string, names []string) []SheetRef {
+	ids := make(map[string]int)
+	if sheets, err := readWorkbookSheets(filePath); err == nil {
+		for _, sheet := range sheets {
+			ids[sheet.Name] = sheet.SheetID
+		}
+	}
+
+	refs := make([]SheetRef, 0, len(names))
+	for _, name := range names {
+		refs = append(refs, SheetRef{Name: name, SheetID: ids[name]})
+	}
+	return refs
+}
+
+// sheetRefsByFile resolves the sheet selections of several files
+func sheetRefsByFile(sheetSelections map[string][]string) map[string][]SheetRef {
+	refs := make(map[string][]SheetRef, len(sheetSelections))
+	for filePath, names := range sheetSelections {
+		refs[filePath] = sheetRefs(filePath, names)
+	}
+	return refs
+}
+
+// reconcileSheets applies a stored selection to the current sheets of a workbook.
+// A sheet is found by name, then by sheetId; renamed and deleted sheets are reported.
+// If the workbook cannot be read the selection is kept as it is.
+func reconcileSheets(filePath string, refs []SheetRef) ([]string, []SheetSelectionIssue) {
+	names := make([]string, 0, len(refs))
+	sheets, err := readWorkbookSheets(filePath)
+	if err != nil {
+		for _, ref := range refs {
+			names = append(names, ref.Name)
+		}
+		return names, nil
+	}
+
+	byName := make(map[string]bool, len(sheets))
+	byID := make(map[int]string, len(sheets))
+	for _, sheet := range sheets {
+		byName[sheet.Name] = true
+		byID[sheet.SheetID] = sheet.Name
+	}
+
+	var issues []SheetSelectionIssue
+	for _, ref := range refs {
+		switch current, found := byID[ref.SheetID]; {
+		case byName[ref.Name]:
+			names = append(names, ref.Name)
+		case ref.SheetID != 0 && found:
+			names = append(names, current)
+			issues = append(issues, SheetSelectionIssue{Sheet: ref.Name, Status: sheetRenamed, NewName: current})
+		default:
+			issues = append(issues, SheetSelectionIssue{Sheet: ref.Name, Status: sheetMissing})
+		}
+	}
+
+	// With every selected sheet gone, an empty selection would export the whole workbook;
+	// keep the names so the conversion reports the missing sheets instead
+	if len(names) == 0 && len(refs) > 0 {
+		for _, ref := range refs {
+			names = append(names, ref.Name)
+		}
+	}
+	return names, issues
+}
+
+// namedSheetRefs returns the stored references of a file, or references by name for
+// selections saved before sheet IDs were recorded
+func namedSheetRefs(refs map[string][]SheetRef, filePath string, names []string) []SheetRef {
+	if stored, exists := refs[filePath]; exists {
+		return stored
+	}
+	named := make([]SheetRef, 0, len(names))
+	for _, name := range names {
+		named = append(named, SheetRef{Name: name})
+	}
+	return named
+}
+
+// reconcileConvertedSheets updates the sheet selections of the changed inputs of the last
+// conversion to follow renamed and deleted sheets, and tells the frontend.
+// The caller must hold conversionMu.
+func (a *App) reconcileConvertedSheets(changed []string) {
+	for _, filePath := range changed {
+		refs, exists := a.lastConvertedSheetRefs[filePath]
+		if !exists || len(refs) == 0 {
+			continue
+		}
+
+		names, issues := reconcileSheets(filePath, refs)
+		if len(issues) == 0 {
+			continue
+		}
+
+		if a.lastConvertedSheets == nil {
+			a.lastConvertedSheets = make(map[string][]string)
+		}
+		a.lastConvertedSheets[filePath] = names
+		a.lastConvertedSheetRefs[filePath] = sheetRefs(filePath, names)
+		a.emit(eventSheetSelectionChanged, SheetSelectionChangedEvent{File: filePath, Sheets: names, Issues: issues})
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// writeWorkbook writes an xlsx package whose workbook.xml lists the sheets
+func writeWorkbook(t *testing.T, filePath string, sheets ...workbookSheet) {
+	t.Helper()
+	xml := `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheets>`
+	for _, sheet := range sheets {
+		xml += `<sheet name="` + sheet.Name + `" sheetId="` + strconv.Itoa(sheet.SheetID) + `"/>`
+	}
+	xml += `</sheets></workbook>`
+	writeZip(t, filePath, map[string]string{"xl/workbook.xml": xml})
+}
+
+func TestReconcileSheets(t *testing.T) {
+	dir := t.TempDir()
+	workbook := filepath.Join(dir, "report.xlsx")
+	writeWorkbook(t, workbook,
+		workbookSheet{Name: "Summary", SheetID: 1},
+		workbookSheet{Name: "Sales 2026", SheetID: 2},
+		workbookSheet{Name: "Notes", SheetID: 4},
+	)
+
+	tests := []struct {
+		name       string
+		file       string
+		refs       []SheetRef
+		wantNames  []string
+		wantIssues []SheetSelectionIssue
+	}{
+		{
+			name:      "unchanged sheets",
+			file:      workbook,
+			refs:      []SheetRef{{Name: "Summary", SheetID: 1}, {Name: "Notes", SheetID: 4}},
+			wantNames: []string{"Summary", "Notes"},
+		},
+		{
+			name:       "renamed sheet is found by its ID",
+			file:       workbook,
+			refs:       []SheetRef{{Name: "Summary", SheetID: 1}, {Name: "Sales", SheetID: 2}},
+			wantNames:  []string{"Summary", "Sales 2026"},
+			wantIssues: []SheetSelectionIssue{{Sheet: "Sales", Status: sheetRenamed, NewName: "Sales 2026"}},
+		},
+		{
+			name:      "name wins over a reused ID",
+			file:      workbook,
+			refs:      []SheetRef{{Name: "Notes", SheetID: 3}},
+			wantNames: []string{"Notes"},
+		},
+		{
+			name:       "deleted sheet is dropped",
+			file:       workbook,
+			refs:       []SheetRef{{Name: "Summary", SheetID: 1}, {Name: "Draft", SheetID: 3}},
+			wantNames:  []string{"Summary"},
+			wantIssues: []SheetSelectionIssue{{Sheet: "Draft", Status: sheetMissing}},
+		},
+		{
+			name:       "selection without IDs cannot follow renames",
+			file:       workbook,
+			refs:       []SheetRef{{Name: "Sales"}},
+			wantNames:  []string{"Sales"},
+			wantIssues: []SheetSelectionIssue{{Sheet: "Sales", Status: sheetMissing}},
+		},
+		{
+			name: "all sheets missing keeps the names",
+			file: workbook,
+			refs: []SheetRef{{Name: "Draft", SheetID: 3}, {Name: "Old", SheetID: 5}},
+			// An empty selection would export the whole workbook
+			wantNames: []string{"Draft", "Old"},
+			wantIssues: []SheetSelectionIssue{
+				{Sheet: "Draft", Status: sheetMissing},
+				{Sheet: "Old", Status: sheetMissing},
+			},
+		},
+		{
+			name:      "unreadable workbook keeps the selection",
+			file:      filepath.Join(dir, "legacy.xls"),
+			refs:      []SheetRef{{Name: "Sheet1", SheetID: 1}},
+			wantNames: []string{"Sheet1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, issues := reconcileSheets(tt.file, tt.refs)
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("names %q, want %q", names, tt.wantNames)
+			}
+			if !reflect.DeepEqual(issues, tt.wantIssues) {
+				t.Errorf("issues %+v, want %+v", issues, tt.wantIssues)
+			}
+		})
+	}
+}
+
+func TestSheetRefsRecordIDs(t *testing.T) {
+	dir := t.TempDir()
+	workbook := filepath.Join(dir, "report.xlsx")
+	writeWorkbook(t, workbook, workbookSheet{Name: "Summary", SheetID: 1}, workbookSheet{Name: "Sales", SheetID: 2})
+
+	got := sheetRefs(workbook, []string{"Sales", "Deleted"})
+	want := []SheetRef{{Name: "Sales", SheetID: 2}, {Name: "Deleted"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package main
+
+import "os"
+
+// lockFile cannot lock across processes on this platform; the store still serializes its own goroutines
+func lockFile(f *os.File, exclusive bool) error {
+	return nil
+}
+
+// unlockFile releases the lock taken by lockFile
+func unlockFile(f *os.File) error {
+	return nil
+}
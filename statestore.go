@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stateLockName is the lock file shared by all app instances using a state directory
+const stateLockName = ".lock"
+
+// stateBackupSuffix names the last good copy kept next to each state file
+const stateBackupSuffix = ".bak"
+
+// errStateCorrupt is returned when neither a state file nor its last good copy can be read
+var errStateCorrupt = errors.New("state file is corrupt")
+
+// stateEnvelope is the stored form of a state file.
+// The checksum covers the compact JSON of Data, so a torn or edited file is detected.
+type stateEnvelope struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	SavedAt       time.Time       `json:"savedAt"`
+	Checksum      string          `json:"checksum"` // SHA-256 of the compact Data
+	Data          json.RawMessage `json:"data"`
+}
+
+// stateEntry is a state file found by List
+type stateEntry struct {
+	Name    string
+	ModTime time.Time
+}
+
+// stateStore keeps the app state as JSON files in one directory.
+// Writes go to a temporary file that is renamed into place, so a crash never leaves a partial file.
+// The previous good version is kept as a backup and used when the current file is corrupt.
+// A lock file serializes access between goroutines and between app instances.
+type stateStore struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+// newStateStore creates a store in dir; the directory is created on the first write
+func newStateStore(dir string) *stateStore {
+	return &stateStore{dir: dir}
+}
+
+// Load reads the state file name into v and reports whether it exists.
+// If the file is missing or corrupt, its last good copy is read instead; the file itself
+// is left as it is until the next Save replaces it.
+func (s *stateStore) Load(name string, v interface{}) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	unlock, err := s.lock(false)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	return s.loadLocked(name, v)
+}
+
+// Save writes v to the state file name
+func (s *stateStore) Save(name string, v interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return s.saveLocked(name, v)
+}
+
+// Update reads the state file name into v, lets update change it and writes it back,
+// without another instance writing in between. update receives whether the file existed.
+func (s *stateStore) Update(name string, v interface{}, update func(exists bool) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	exists, err := s.loadLocked(name, v)
+	if err != nil && !errors.Is(err, errStateCorrupt) {
+		return err
+	}
+	if err := update(exists); err != nil {
+		return err
+	}
+	return s.saveLocked(name, v)
+}
+
+// Remove deletes the state file name and its backup
+func (s *stateStore) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	os.Remove(s.path(name) + stateBackupSuffix)
+	if err := os.Remove(s.path(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// List returns the state files whose names start with prefix
+func (s *stateStore) List(prefix string) ([]stateEntry, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var found []stateEntry
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		found = append(found, stateEntry{Name: name, ModTime: info.ModTime()})
+	}
+	return found, nil
+}
+
+// path returns the path of the state file name
+func (s *stateStore) path(name string) string {
+	return filepath.Join(s.dir, name)
+}
+
+// lock takes the cross-process lock of the store directory
+func (s *stateStore) lock(exclusive bool) (func(), error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %v", err)
+	}
+	f, err := os.OpenFile(s.path(stateLockName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state lock: %v", err)
+	}
+	if err := lockFile(f, exclusive); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock state: %v", err)
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// loadLocked reads name, falling back to its last good copy
+func (s *stateStore) loadLocked(name string, v interface{}) (bool, error) {
+	data, err := readStateFile(s.path(name))
+	if err == nil {
+		return true, json.Unmarshal(data, v)
+	}
+	if os.IsNotExist(err) {
+		// A save may have been interrupted between moving the backup and the new file into place
+		if data, err := readStateFile(s.path(name) + stateBackupSuffix); err == nil {
+			return true, json.Unmarshal(data, v)
+		}
+		return false, nil
+	}
+
+	if data, backupErr := readStateFile(s.path(name) + stateBackupSuffix); backupErr == nil {
+		fmt.Printf("Warning: %s: %v; using the last good copy\n", name, err)
+		return true, json.Unmarshal(data, v)
+	}
+	return false, fmt.Errorf("%w: %s: %v", errStateCorrupt, name, err)
+}
+
+// saveLocked writes v to name, keeping the current file as backup if it is intact
+func (s *stateStore) saveLocked(name string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %v", name, err)
+	}
+	sum := sha256.Sum256(data)
+	envelope, err := json.MarshalIndent(stateEnvelope{
+		SchemaVersion: stateSchemaVersion,
+		SavedAt:       time.Now(),
+		Checksum:      hex.EncodeToString(sum[:]),
+		Data:          data,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %v", name, err)
+	}
+
+	filePath := s.path(name)
+	if _, err := readStateFile(filePath); err == nil {
+		if err := os.Rename(filePath, filePath+stateBackupSuffix); err != nil {
+			return fmt.Errorf("failed to back up %s: %v", name, err)
+		}
+	}
+	if err := writeFileAtomic(filePath, envelope, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", name, err)
+	}
+	return nil
+}
+
+// readStateFile returns the data of a state file after checking its version and checksum
+func readStateFile(filePath string) ([]byte, error) {
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var envelope stateEnvelope
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	if envelope.SchemaVersion != stateSchemaVersion {
+		return nil, fmt.Errorf("unsupported state schema version: %d", envelope.SchemaVersion)
+	}
+
+	var data bytes.Buffer
+	if err := json.Compact(&data, envelope.Data); err != nil {
+		return nil, fmt.Errorf("invalid data: %v", err)
+	}
+	sum := sha256.Sum256(data.Bytes())
+	if hex.EncodeToString(sum[:]) != envelope.Checksum {
+		return nil, fmt.Errorf("checksum mismatch")
+	}
+	return data.Bytes(), nil
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory and renames it to filePath
+func writeFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpFile.Name()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type testState struct {
+	Value string `json:"value"`
+}
+
+// saveVersions saves each value in turn, leaving the last as the state file and the one before as its backup
+func saveVersions(t *testing.T, store *stateStore, name string, values ...string) {
+	t.Helper()
+	for _, value := range values {
+		if err := store.Save(name, testState{Value: value}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// loadValue loads name and fails the test unless it exists and reads without error
+func loadValue(t *testing.T, store *stateStore, name string) string {
+	t.Helper()
+	var state testState
+	exists, err := store.Load(name, &state)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !exists {
+		t.Fatal("Load reported a missing file")
+	}
+	return state.Value
+}
+
+func TestStateStoreSaveKeepsBackup(t *testing.T) {
+	store := newStateStore(t.TempDir())
+	saveVersions(t, store, "session.json", "v1", "v2")
+
+	if got := loadValue(t, store, "session.json"); got != "v2" {
+		t.Fatalf("got %q, want v2", got)
+	}
+	data, err := readStateFile(store.path("session.json") + stateBackupSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"value":"v1"}` {
+		t.Fatalf("backup %s, want v1", data)
+	}
+}
+
+func TestStateStoreFallsBackToBackup(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(t *testing.T, filePath string)
+		want    string
+	}{
+		{
+			name: "torn write",
+			want: "v1",
+			corrupt: func(t *testing.T, filePath string) {
+				raw, err := os.ReadFile(filePath)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filePath, raw[:len(raw)/2], 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "edited data",
+			want: "v1",
+			corrupt: func(t *testing.T, filePath string) {
+				raw, err := os.ReadFile(filePath)
+				if err != nil {
+					t.Fatal(err)
+				}
+				edited := strings.Replace(string(raw), `"v2"`, `"v3"`, 1)
+				if err := os.WriteFile(filePath, []byte(edited), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "missing after the backup was moved into place",
+			want: "v2",
+			corrupt: func(t *testing.T, filePath string) {
+				// Save renames the current file to the backup before writing the new one
+				if err := os.Rename(filePath, filePath+stateBackupSuffix); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newStateStore(t.TempDir())
+			saveVersions(t, store, "session.json", "v1", "v2")
+			tt.corrupt(t, store.path("session.json"))
+
+			if got := loadValue(t, store, "session.json"); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStateStoreDetectsChecksumMismatch(t *testing.T) {
+	store := newStateStore(t.TempDir())
+	saveVersions(t, store, "session.json", "v1")
+
+	filePath := store.path("session.json")
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Still valid JSON, so only the checksum can tell
+	edited := strings.Replace(string(raw), `"v1"`, `"v9"`, 1)
+	if err := os.WriteFile(filePath, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := readStateFile(filePath); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("readStateFile error %v, want checksum mismatch", err)
+	}
+	var state testState
+	if _, err := store.Load("session.json", &state); !errors.Is(err, errStateCorrupt) {
+		t.Fatalf("Load error %v, want errStateCorrupt", err)
+	}
+}
+
+func TestStateStoreSaveReplacesCorruptFile(t *testing.T) {
+	store := newStateStore(t.TempDir())
+	saveVersions(t, store, "session.json", "v1", "v2")
+	filePath := store.path("session.json")
+	if err := os.WriteFile(filePath, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The corrupt file must not replace the last good copy
+	saveVersions(t, store, "session.json", "v3")
+	if got := loadValue(t, store, "session.json"); got != "v3" {
+		t.Fatalf("got %q, want v3", got)
+	}
+	data, err := readStateFile(filePath + stateBackupSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"value":"v1"}` {
+		t.Fatalf("backup %s, want v1", data)
+	}
+}
+
+func TestStateStoreMissingFile(t *testing.T) {
+	store := newStateStore(filepath.Join(t.TempDir(), "state"))
+
+	var state testState
+	exists, err := store.Load("session.json", &state)
+	if err != nil || exists {
+		t.Fatalf("Load = %v, %v, want false, nil", exists, err)
+	}
+}
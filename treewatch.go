@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Tree events published when the working directory changes on disk
+const (
+	eventTreeAdded    = "tree:added"
+	eventTreeRemoved  = "tree:removed"
+	eventTreeRenamed  = "tree:renamed"
+	eventTreeModified = "tree:modified"
+)
+
+// treeRenameWindow is how long a rename waits for the matching create of the new name
+const treeRenameWindow = 100 * time.Millisecond
+
+// treeModifyDebounce groups the writes of one save into a single tree:modified
+const treeModifyDebounce = 300 * time.Millisecond
+
+// TreeEvent is the payload of the tree:* events
+type TreeEvent struct {
+	Path    string    `json:"path"`              // Affected entry; the new path for renames
+	OldPath string    `json:"oldPath,omitempty"` // Previous path, for renames
+	Parent  string    `json:"parent"`            // Directory containing Path
+	File    *FileInfo `json:"file,omitempty"`    // Entry as GetDirectoryTree would list it; nil for removals
+}
+
+// treeWatcher follows the working directory recursively and publishes tree:* events.
+// It applies the same filters and depth limit as GetDirectoryTree.
+type treeWatcher struct {
+	app      *App
+	root     string
+	maxDepth int // Directory levels listed, from the settings when the watcher started
+	watcher  *fsnotify.Watcher
+	modified *fileDebouncer
+	done     chan struct{}
+}
+
+// watchTree starts following dir, replacing the previous tree watcher
+func (a *App) watchTree(dir string) {
+	a.stopTreeWatcher()
+	if dir == "" {
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		runtime.LogWarning(a.ctx, fmt.Sprintf("Tree watcher failed: %v", err))
+		return
+	}
+
+	t := &treeWatcher{
+		app:      a,
+		root:     filepath.Clean(dir),
+		maxDepth: a.currentSettings().TreeDepth,
+		watcher:  watcher,
+		done:     make(chan struct{}),
+	}
+	t.modified = newFileDebouncer(realClock{}, treeModifyDebounce, t.emitModified)
+	t.addDir(t.root)
+
+	a.treeWatcher = t
+	go t.run()
+}
+
+// stopTreeWatcher stops the current tree watcher, if any
+func (a *App) stopTreeWatcher() {
+	if a.treeWatcher == nil {
+		return
+	}
+	a.treeWatcher.modified.Stop()
+	a.treeWatcher.watcher.Close()
+	<-a.treeWatcher.done
+	a.treeWatcher = nil
+}
+
+// run handles events until the watcher is closed
+func (t *treeWatcher) run() {
+	defer close(t.done)
+
+	// A rename reports the old name first; the new name follows as a create
+	var renamedFrom string
+	renameTimer := time.NewTimer(time.Hour)
+	renameTimer.Stop()
+
+	flushRename := func() {
+		if renamedFrom != "" {
+			t.publish(eventTreeRemoved, TreeEvent{Path: renamedFrom, Parent: filepath.Dir(renamedFrom)})
+			renamedFrom = ""
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-t.watcher.Events:
+			if !ok {
+				return
+			}
+			path := filepath.Clean(event.Name)
+
+			switch {
+			case event.Op&fsnotify.Create != 0:
+				file, ok := t.describe(path)
+				if !ok {
+					continue
+				}
+				if file.IsDir {
+					t.addDir(path)
+				}
+				if renamedFrom != "" {
+					renameTimer.Stop()
+					t.publish(eventTreeRenamed, TreeEvent{Path: path, OldPath: renamedFrom, Parent: filepath.Dir(path), File: file})
+					renamedFrom = ""
+					continue
+				}
+				t.publish(eventTreeAdded, TreeEvent{Path: path, Parent: filepath.Dir(path), File: file})
+
+			case event.Op&fsnotify.Rename != 0:
+				if !t.listed(path) {
+					continue
+				}
+				flushRename()
+				renamedFrom = path
+				renameTimer.Reset(treeRenameWindow)
+
+			case event.Op&fsnotify.Remove != 0:
+				if !t.listed(path) {
+					continue
+				}
+				t.publish(eventTreeRemoved, TreeEvent{Path: path, Parent: filepath.Dir(path)})
+
+			case event.Op&fsnotify.Write != 0:
+				if t.listed(path) && isOfficeFile(strings.ToLower(filepath.Ext(path))) {
+					t.modified.Trigger(path, event.Op.String())
+				}
+			}
+
+		case <-renameTimer.C:
+			// Moved out of the tree, or the new name is filtered out
+			flushRename()
+
+		case _, ok := <-t.watcher.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// emitModified publishes tree:modified once a file has been quiet
+func (t *treeWatcher) emitModified(path, _ string) {
+	file, ok := t.describe(path)
+	if !ok || file.IsDir {
+		return
+	}
+	t.publish(eventTreeModified, TreeEvent{Path: path, Parent: filepath.Dir(path), File: file})
+}
+
+// publish sends a tree event to the frontend and API clients
+func (t *treeWatcher) publish(eventType string, event TreeEvent) {
+	t.app.emit(eventType, event)
+}
+
+// depth returns how many directories lie between the root and the directory containing path
+func (t *treeWatcher) depth(path string) int {
+	rel, err := filepath.Rel(t.root, filepath.Dir(path))
+	if err != nil || rel == "." {
+		return 0
+	}
+	return len(strings.Split(rel, string(filepath.Separator)))
+}
+
+// listed reports whether path lies within the listed depth.
+// Removed entries cannot be inspected, so the frontend ignores paths it does not show.
+func (t *treeWatcher) listed(path string) bool {
+	return t.depth(path) < t.maxDepth
+}
+
+// describe returns the entry at path as GetDirectoryTree lists it, or false if it is filtered out
+func (t *treeWatcher) describe(path string) (*FileInfo, bool) {
+	depth := t.depth(path)
+	if depth >= t.maxDepth {
+		return nil, false
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	if !info.IsDir() && (!isOfficeFile(strings.ToLower(filepath.Ext(path))) || isOfficeTempFile(info.Name())) {
+		return nil, false
+	}
+
+	file := &FileInfo{
+		Name:    info.Name(),
+		Path:    path,
+		Size:    info.Size(),
+		IsDir:   info.IsDir(),
+		ModTime: info.ModTime().Format("2006-01-02 15:04:05"),
+	}
+	if info.IsDir() {
+		if children, err := t.app.buildDirectoryTree(path, depth+1, t.maxDepth); err == nil {
+			file.Children = children
+		}
+	}
+	return file, true
+}
+
+// addDir watches dir and its subdirectories whose contents GetDirectoryTree lists
+func (t *treeWatcher) addDir(dir string) {
+	// Contents of dir are listed when dir itself is above the depth limit
+	if dir != t.root && t.depth(dir)+1 >= t.maxDepth {
+		return
+	}
+	if err := t.watcher.Add(dir); err != nil {
+		return
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			t.addDir(filepath.Join(dir, entry.Name()))
+		}
+	}
+}
@@ -3,28 +3,43 @@ package main
 import (
 	"context"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/fsnotify/fsnotify"
+	"github.com/wailsapp/wails/v2/pkg/menu"
 )
 
 // App struct
 type App struct {
-	ctx                 context.Context
-	converter           *OfficeConverter
-	initialDir          string // Initial directory to open
-	httpServer          *http.Server
-	httpPort            int
-	watcher             *fsnotify.Watcher
-	watchedDir          string
-	lastConvertedFiles  []string
-	lastConvertedSheets map[string][]string
-	autoUpdateEnabled   bool
-	fileModTimes        map[string]time.Time // Track file modification times
-	pollingTicker       *time.Ticker
-	currentPdfPath      string // Current PDF file path in temp
-	savedPdfPath        string // Last saved PDF path
-	hasUnsavedChanges   bool   // Whether there are unsaved changes
+	ctx                    context.Context
+	converter              *OfficeConverter
+	initialDir             string // Initial directory to open
+	httpServer             *http.Server
+	httpPort               int
+	httpToken              string          // Per-session token required by the HTTP server
+	outputs                *outputRegistry // Files the HTTP server may serve
+	monitor                *fileMonitor    // Detects input changes and schedules regeneration
+	treeWatcher            *treeWatcher    // Pushes changes of the working directory tree
+	lastConvertedFiles     []string
+	lastConvertedSheets    map[string][]string
+	lastConvertedSheetRefs map[string][]SheetRef // Sheet IDs of lastConvertedSheets, to follow renamed sheets
+	currentPdfPath         string                // Current PDF file path in temp
+	savedPdfPath           string                // Last saved PDF path
+	hasUnsavedChanges      bool                  // Whether there are unsaved changes
+	recipePath             string                // Recipe file opened or saved last
+	recipeOptions          *BundleOptions        // Page selections, options and output of the active recipe
+	recipeMu               sync.Mutex            // Guards recipePath and recipeOptions, which bindings and the API share
+	conversionMu           sync.Mutex            // Serializes conversions from the GUI, auto-update and the API; guards lastConverted*
+	apiJobs                *apiJobStore          // Conversions started through the REST API
+	events                 *eventBus             // App events for the Wails frontend and SSE clients
+	share                  *shareServer          // LAN share server, nil when not sharing
+	shareMu                sync.Mutex
+	state                  *stateStore // Sessions, sheet selections and directory history
+	workspaceMenu          *menu.Menu  // Menu listing the recent workspaces
+	settings               Settings    // User preferences; read through currentSettings
+	settingsMu             sync.RWMutex
+	lastReport             atomic.Pointer[ConversionReport] // Report of the most recent conversion run; read without waiting for a running conversion
 }
 
 // FileInfo represents file information
@@ -42,6 +57,7 @@ type ExcelSheetInfo struct {
 	Name    string `json:"name"`
 	Visible bool   `json:"visible"`
 	Index   int    `json:"index"`
+	SheetID int    `json:"sheetId,omitempty"` // Stable ID kept across renames; xlsx and xlsm only
 }
 
 // ConversionStatus represents the status of a conversion operation
@@ -51,15 +67,17 @@ type ConversionStatus struct {
 	Progress     int    `json:"progress"`     // Progress percentage
 	OutputPath   string `json:"outputPath"`   // Final output PDF path
 	ErrorMessage string `json:"errorMessage"` // Error message if status is "error"
+	Unchanged    bool   `json:"unchanged"`    // Output bytes are identical to the previous preview
 }
 
 // SheetSelectionCache represents cached sheet selections for a directory
 type SheetSelectionCache struct {
-	DirectoryHash string              `json:"directoryHash"` // MD5 hash of directory path
-	LastUpdated   time.Time           `json:"lastUpdated"`   // When cache was last updated
-	Selections    map[string][]string `json:"selections"`    // File path -> selected sheets
-	FileHashes    map[string]string   `json:"fileHashes"`    // File path -> file content hash
-	ExpiryTime    time.Time           `json:"expiryTime"`    // When cache expires
+	DirectoryHash string                `json:"directoryHash"`       // MD5 hash of directory path
+	LastUpdated   time.Time             `json:"lastUpdated"`         // When cache was last updated
+	Selections    map[string][]string   `json:"selections"`          // File path -> selected sheets
+	FileHashes    map[string]string     `json:"fileHashes"`          // File path -> file content hash
+	ExpiryTime    time.Time             `json:"expiryTime"`          // When cache expires
+	SheetRefs     map[string][]SheetRef `json:"sheetRefs,omitempty"` // File path -> selected sheets with their IDs
 }
 
 // DirectoryHistory represents history of directory usage
@@ -72,13 +90,19 @@ type DirectoryHistory struct {
 
 // DirectorySessionCache represents cached state for a specific directory
 type DirectorySessionCache struct {
-	DirectoryPath   string              `json:"directoryPath"`   // Directory path
-	DirectoryHash   string              `json:"directoryHash"`   // MD5 hash of directory path
-	LastUpdated     time.Time           `json:"lastUpdated"`     // When cache was last updated
-	SelectedFiles   []string            `json:"selectedFiles"`   // List of selected file paths
-	ExpandedFolders []string            `json:"expandedFolders"` // List of expanded folder paths
-	CurrentFile     string              `json:"currentFile"`     // Currently selected file
-	SheetSelections map[string][]string `json:"sheetSelections"` // File path -> selected sheets
-	FileHashes      map[string]string   `json:"fileHashes"`      // File path -> file content hash for validation
-	ExpiryTime      time.Time           `json:"expiryTime"`      // When cache expires
+	DirectoryPath   string                           `json:"directoryPath"`            // Directory path
+	DirectoryHash   string                           `json:"directoryHash"`            // MD5 hash of directory path
+	LastUpdated     time.Time                        `json:"lastUpdated"`              // When cache was last updated
+	SelectedFiles   []string                         `json:"selectedFiles"`            // List of selected file paths
+	ExpandedFolders []string                         `json:"expandedFolders"`          // List of expanded folder paths
+	CurrentFile     string                           `json:"currentFile"`              // Currently selected file
+	SheetSelections map[string][]string              `json:"sheetSelections"`          // File path -> selected sheets
+	FileHashes      map[string]string                `json:"fileHashes"`               // File path -> file content hash for validation
+	ExpiryTime      time.Time                        `json:"expiryTime"`               // When cache expires
+	Workspace       string                           `json:"workspace"`                // ID of the workspace the session belongs to
+	SheetRefs       map[string][]SheetRef            `json:"sheetRefs,omitempty"`      // File path -> selected sheets with their IDs
+	SheetIssues     map[string][]SheetSelectionIssue `json:"sheetIssues,omitempty"`    // File path -> renamed or deleted sheets, when loaded
+	PageSelections  map[string]string                `json:"pageSelections,omitempty"` // File path -> page selection such as "1-3,5"
+	PostProcess     *PostProcessOptions              `json:"postProcess,omitempty"`    // Options applied after merging
+	OutputPath      string                           `json:"outputPath,omitempty"`     // Destination PDF
 }
@@ -1,224 +1,681 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-// initFileWatcher initializes the file system watcher
+// Polling of inputs on network mounts, where file system events do not arrive.
+// The interval doubles while nothing changes, up to maxPollingInterval.
+const (
+	pollingInterval    = 2 * time.Second // Default interval after a change
+	minPollingInterval = 500 * time.Millisecond
+	maxPollingInterval = 30 * time.Second
+)
+
+// Polling reads a whole file only when its metadata cannot rule out a change,
+// since hashing re-downloads it from the network share
+const (
+	pollSettleTime = 10 * time.Second // A file modified this close to its last hash may change without a new time
+	pollHashEvery  = 10               // Hash unchanged-looking files on every nth poll anyway
+)
+
+// fileSnapshot is what polling compares: size and modification time, and the content hash
+// for changes that network file systems report with a coarse or unchanged modification time
+type fileSnapshot struct {
+	size    int64
+	modTime time.Time
+	hash    string
+	hashed  time.Time // When the hash was taken
+}
+
+// settled reports whether the file was last modified long enough before it was hashed
+// that a later write would show a different modification time, even with a coarse resolution
+func (s fileSnapshot) settled() bool {
+	age := s.hashed.Sub(s.modTime)
+	return age >= pollSettleTime || age <= -pollSettleTime
+}
+
+// fileMonitor detects changes of the converted inputs and schedules regeneration.
+// It reaches the file system, time and the frontend only through its dependencies,
+// so change detection can be tested without real files or timers.
+type fileMonitor struct {
+	source  eventSource
+	clock   clock
+	fs      fileStater
+	emitter eventEmitter
+
+	regenerate func(changed []string)         // Rebuilds the preview, reconverting changed inputs
+	renamed    func(oldPath, newPath string)  // Carries state outside the monitor over to a new path
+	warn       func(message string)           // Reports problems that do not stop monitoring
+	remote     func(filePath string) bool     // Whether file system events for filePath cannot be trusted
+	links      func(filePath string) []string // Files an input links to, such as external workbooks
+
+	mu           sync.Mutex
+	inputs       []string
+	autoUpdate   bool
+	changed      map[string]bool         // Inputs changed since the last regeneration
+	dependencies map[string][]string     // Input -> linked files
+	dependents   map[string][]string     // Linked file, cleaned -> inputs linking to it
+	changedVia   map[string]string       // Input -> linked file whose change is pending for it
+	polled       map[string]fileSnapshot // Inputs and linked files checked by polling, with what was last seen
+	pollAll      bool                    // No file system events at all; everything is polled
+	interval     time.Duration           // Configured polling interval
+	nextInterval time.Duration           // Current interval, backed off while idle
+	pollTimer    clockTimer
+	polls        int // Polls run, to hash everything every pollHashEvery polls
+	done         chan struct{}
+
+	dirs            *dirWatchSet    // Directories of the inputs
+	debouncer       *fileDebouncer  // Groups change events per file
+	lockedDebouncer *fileDebouncer  // Holds back changes of inputs open in Office
+	locks           *lockTracker    // Inputs open in Office
+	renames         *renameTracker  // Follows inputs that are renamed or moved
+	regen           *regenScheduler // Runs regenerations one at a time
+}
+
+// newFileMonitor creates a monitor with auto-update enabled and no inputs
+func newFileMonitor(source eventSource, clk clock, fs fileStater, emitter eventEmitter) *fileMonitor {
+	_, pollAll := source.(pollingOnlySource)
+	m := &fileMonitor{
+		source:     source,
+		clock:      clk,
+		fs:         fs,
+		emitter:    emitter,
+		regenerate: func([]string) {},
+		renamed:    func(string, string) {},
+		warn:       func(string) {},
+		remote:     isNetworkPath,
+		links:      linkedFiles,
+		autoUpdate: true,
+		polled:     make(map[string]fileSnapshot),
+		changedVia: make(map[string]string),
+		pollAll:    pollAll,
+		interval:   pollingInterval,
+		done:       make(chan struct{}),
+		dirs:       newDirWatchSet(source),
+		locks:      newLockTracker(),
+		renames:    newRenameTracker(clk, fs),
+	}
+	m.debouncer = newFileDebouncer(clk, autoUpdateDebounce, m.handleSettled)
+	m.lockedDebouncer = newFileDebouncer(clk, lockQuietPeriod, m.handleSettled)
+	m.regen = newRegenScheduler(func() { m.regenerate(m.takeChanged()) })
+	return m
+}
+
+// initFileWatcher starts the file monitor; without a file system watcher it relies on polling
 func (a *App) initFileWatcher() {
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return
+	var source eventSource = pollingOnlySource{}
+	if watcher, err := newFsnotifySource(); err == nil {
+		source = watcher
+	} else {
+		runtime.LogWarning(a.ctx, fmt.Sprintf("File watcher not available, using polling only: %v", err))
+	}
+
+	settings := a.currentSettings()
+	a.monitor = newFileMonitor(source, realClock{}, osFS{}, a)
+	a.monitor.SetAutoUpdate(settings.AutoUpdate)
+	a.monitor.SetPollingInterval(time.Duration(settings.PollingIntervalMs) * time.Millisecond)
+	a.monitor.regenerate = a.autoRegeneratePDF
+	a.monitor.renamed = a.applyInputRename
+	a.monitor.warn = func(message string) {
+		runtime.LogWarning(a.ctx, message)
 	}
-	a.watcher = watcher
 
 	// Start watching in a goroutine
-	go a.watchFiles()
+	go a.monitor.run()
 }
 
-// watchFiles runs the file watching loop
-func (a *App) watchFiles() {
+// run handles file system events until the monitor is stopped
+func (m *fileMonitor) run() {
 	for {
 		select {
-		case event, ok := <-a.watcher.Events:
+		case <-m.done:
+			return
+		case event, ok := <-m.source.Events():
 			if !ok {
 				return
 			}
-			a.handleFileEvent(event)
-		case err, ok := <-a.watcher.Errors:
+			m.handleEvent(event)
+		case err, ok := <-m.source.Errors():
 			if !ok {
 				return
 			}
-			runtime.LogError(a.ctx, fmt.Sprintf("File watcher error: %v", err))
+			m.warn(fmt.Sprintf("File watcher error: %v", err))
 		}
 	}
 }
 
-// handleFileEvent processes file system events
-func (a *App) handleFileEvent(event fsnotify.Event) {
-	if !a.autoUpdateEnabled || len(a.lastConvertedFiles) == 0 {
-		return
+// Stop ends watching and polling and drops pending changes
+func (m *fileMonitor) Stop() {
+	m.mu.Lock()
+	if m.pollTimer != nil {
+		m.pollTimer.Stop()
+		m.pollTimer = nil
 	}
+	select {
+	case <-m.done:
+	default:
+		close(m.done)
+	}
+	m.mu.Unlock()
 
-	// Check if the changed file is one of our converted files or related to them
-	eventPath := filepath.Clean(event.Name)
-	isWatchedFile := false
-	watchedFilePath := ""
+	m.debouncer.Stop()
+	m.lockedDebouncer.Stop()
+	m.source.Close()
+}
 
-	for _, convertedFile := range a.lastConvertedFiles {
-		cleanConvertedFile := filepath.Clean(convertedFile)
+// SetInputs makes filePaths the monitored inputs, as converted just now.
+// The files they link to are watched as well; a change to one regenerates the inputs linking to it.
+// Inputs and linked files on network mounts are polled in addition to watching their directories.
+func (m *fileMonitor) SetInputs(filePaths []string) {
+	dependencies := make(map[string][]string)
+	watched := append([]string(nil), filePaths...)
+	for _, filePath := range filePaths {
+		if links := m.links(filePath); len(links) > 0 {
+			dependencies[filePath] = links
+			watched = append(watched, links...)
+		}
+	}
 
-		// Direct match
-		if cleanConvertedFile == eventPath {
-			isWatchedFile = true
-			watchedFilePath = convertedFile
-			break
+	polled := make(map[string]fileSnapshot)
+	for _, filePath := range watched {
+		if !m.pollAll && !m.remote(filePath) {
+			continue
 		}
+		if snapshot, err := m.snapshot(filePath); err == nil {
+			polled[filePath] = snapshot
+		}
+	}
 
-		// Check for Excel temporary files (starts with ~$ or similar patterns)
-		eventFileName := filepath.Base(eventPath)
-		convertedFileName := filepath.Base(cleanConvertedFile)
-		eventDir := filepath.Dir(eventPath)
-		convertedDir := filepath.Dir(cleanConvertedFile)
+	m.mu.Lock()
+	m.inputs = append([]string(nil), filePaths...)
+	m.polled = polled
+	previous := m.dependencies
+	m.setDependenciesLocked(dependencies)
+	m.mu.Unlock()
 
-		// Excel temporary file patterns
-		if eventDir == convertedDir &&
-			(strings.HasPrefix(eventFileName, "~$") ||
-				strings.HasPrefix(eventFileName, ".~") ||
-				strings.Contains(eventFileName, convertedFileName)) {
-			isWatchedFile = true
-			watchedFilePath = convertedFile
-			break
+	m.renames.Record(filePaths)
+	for _, err := range m.dirs.SetFiles(watched) {
+		m.warn(err.Error())
+	}
+	m.refreshLocks(filePaths)
+
+	// Tell the frontend about inputs whose links changed
+	for _, filePath := range filePaths {
+		if !slices.Equal(previous[filePath], dependencies[filePath]) {
+			m.emitter.emit(eventFileDependencies, FileDependenciesEvent{File: filePath, Dependencies: dependencies[filePath]})
+		}
+	}
+}
+
+// setDependenciesLocked replaces the linked files of the inputs; m.mu must be held
+func (m *fileMonitor) setDependenciesLocked(dependencies map[string][]string) {
+	m.dependencies = dependencies
+	m.dependents = make(map[string][]string)
+	for filePath, links := range dependencies {
+		for _, link := range links {
+			m.dependents[filepath.Clean(link)] = append(m.dependents[filepath.Clean(link)], filePath)
+		}
+	}
+}
+
+// Dependencies returns the linked files of the inputs that have any
+func (m *fileMonitor) Dependencies() map[string][]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dependencies := make(map[string][]string, len(m.dependencies))
+	for filePath, links := range m.dependencies {
+		dependencies[filePath] = append([]string(nil), links...)
+	}
+	return dependencies
+}
+
+// dependentsOf returns the inputs linking to filePath
+func (m *fileMonitor) dependentsOf(filePath string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.dependents[filepath.Clean(filePath)]...)
+}
+
+// isInput reports whether filePath is one of the inputs
+func (m *fileMonitor) isInput(filePath string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, inputPath := range m.inputs {
+		if inputPath == filePath {
+			return true
 		}
 	}
+	return false
+}
+
+// Inputs returns the monitored inputs
+func (m *fileMonitor) Inputs() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.inputs...)
+}
+
+// SetAutoUpdate enables or disables regeneration on changes
+func (m *fileMonitor) SetAutoUpdate(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.autoUpdate = enabled
+}
+
+// AutoUpdate reports whether changes regenerate the preview
+func (m *fileMonitor) AutoUpdate() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.autoUpdate
+}
+
+// handleEvent processes file system events
+func (m *fileMonitor) handleEvent(event fsnotify.Event) {
+	eventPath := filepath.Clean(event.Name)
+	inputs := m.Inputs()
+
+	// Lock and temporary files only tell which inputs are open in Office
+	if isOfficeTempFile(filepath.Base(eventPath)) {
+		for _, inputPath := range inputs {
+			if filepath.Dir(filepath.Clean(inputPath)) == filepath.Dir(eventPath) {
+				m.refreshLock(inputPath)
+			}
+		}
+		return
+	}
+
+	// Pair the rename of an input with the create event of its new name
+	if event.Op&fsnotify.Create == fsnotify.Create {
+		if oldPath, renamed := m.renames.Match(eventPath); renamed {
+			m.applyRename(oldPath, eventPath)
+			return
+		}
+	}
+	if event.Op&fsnotify.Rename == fsnotify.Rename {
+		m.renames.Renamed(eventPath)
+	}
 
-	if !isWatchedFile {
+	if !m.AutoUpdate() || len(inputs) == 0 {
 		return
 	}
 
 	// Process Write, Remove, Create, and Rename events
-	if event.Op&fsnotify.Write == fsnotify.Write ||
-		event.Op&fsnotify.Remove == fsnotify.Remove ||
-		event.Op&fsnotify.Create == fsnotify.Create ||
-		event.Op&fsnotify.Rename == fsnotify.Rename {
-
-		// Debounce - wait a short time for multiple events
-		time.Sleep(500 * time.Millisecond)
-
-		// Check if the actual target file still exists and has been modified
-		if watchedFilePath != "" {
-			if _, err := os.Stat(watchedFilePath); os.IsNotExist(err) {
-				return // Target file was deleted
-			}
+	if event.Op&(fsnotify.Write|fsnotify.Remove|fsnotify.Create|fsnotify.Rename) == 0 {
+		return
+	}
+
+	// A linked file changed; regenerate the inputs that pull from it
+	for _, inputPath := range m.dependentsOf(eventPath) {
+		m.queueChange(inputPath, event.Op.String(), eventPath)
+	}
+
+	// Check if the changed file is one of the inputs or related to them
+	watchedFilePath := ""
+	for _, inputPath := range inputs {
+		cleanInputPath := filepath.Clean(inputPath)
+
+		// Direct match
+		if cleanInputPath == eventPath {
+			watchedFilePath = inputPath
+			break
 		}
 
-		// Emit event to frontend to trigger auto-update
-		runtime.EventsEmit(a.ctx, "file-changed", map[string]interface{}{
-			"file":      watchedFilePath,
-			"operation": event.Op.String(),
-		})
+		// Backup and save copies named after the input
+		if filepath.Dir(eventPath) == filepath.Dir(cleanInputPath) &&
+			strings.Contains(filepath.Base(eventPath), filepath.Base(cleanInputPath)) {
+			watchedFilePath = inputPath
+			break
+		}
+	}
 
-		// Auto-regenerate PDF
-		go a.autoRegeneratePDF()
+	if watchedFilePath == "" {
+		return
 	}
+
+	// Group the burst of events of one save per file
+	m.queueChange(watchedFilePath, event.Op.String(), "")
 }
 
-// StartWatchingDirectory starts watching a directory for file changes
-func (a *App) StartWatchingDirectory(dirPath string) error {
-	if a.watcher == nil {
-		return fmt.Errorf("file watcher not initialized")
+// queueChange schedules regeneration for a changed input, or for an input whose linked file dependency changed.
+// While the input is open in Office, it waits for the lock release or a longer quiet period.
+func (m *fileMonitor) queueChange(filePath, operation, dependency string) {
+	m.mu.Lock()
+	if dependency != "" {
+		m.changedVia[filePath] = dependency
+	} else {
+		delete(m.changedVia, filePath)
 	}
+	m.mu.Unlock()
 
-	// Stop watching previous directory
-	if a.watchedDir != "" {
-		a.watcher.Remove(a.watchedDir)
+	if m.locks.Locked(filePath) {
+		m.lockedDebouncer.Trigger(filePath, operation)
+		return
 	}
+	m.debouncer.Trigger(filePath, operation)
+}
 
-	// Start watching new directory
-	err := a.watcher.Add(dirPath)
-	if err != nil {
-		return err
+// handleSettled runs once a changed input has been quiet for the debounce period
+func (m *fileMonitor) handleSettled(filePath, operation string) {
+	// Check if the actual target file still exists
+	if _, err := m.fs.Stat(filePath); os.IsNotExist(err) {
+		return // Target file was deleted
 	}
 
-	a.watchedDir = dirPath
-	return nil
+	m.mu.Lock()
+	dependency := m.changedVia[filePath]
+	delete(m.changedVia, filePath)
+	m.mu.Unlock()
+
+	// Emit event to frontend to trigger auto-update
+	m.emitter.emit(eventFileChanged, FileChangedEvent{File: filePath, Operation: operation, Dependency: dependency})
+
+	// Only this input needs reconverting; the rest come from the cache
+	m.mu.Lock()
+	if m.changed == nil {
+		m.changed = make(map[string]bool)
+	}
+	m.changed[filePath] = true
+	m.mu.Unlock()
+
+	// Regenerate, at most one run at a time
+	m.regen.Request()
 }
 
-// SetAutoUpdateEnabled enables or disables automatic PDF updates
-func (a *App) SetAutoUpdateEnabled(enabled bool) {
-	a.autoUpdateEnabled = enabled
+// takeChanged returns and clears the inputs changed since the last regeneration
+func (m *fileMonitor) takeChanged() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	changed := make([]string, 0, len(m.changed))
+	for filePath := range m.changed {
+		changed = append(changed, filePath)
+	}
+	m.changed = nil
+	return changed
 }
 
-// GetAutoUpdateEnabled returns current auto-update status
-func (a *App) GetAutoUpdateEnabled() bool {
-	return a.autoUpdateEnabled
+// refreshLocks checks the lock files of all inputs, forgetting files that are no longer inputs
+func (m *fileMonitor) refreshLocks(filePaths []string) {
+	m.locks.Retain(filePaths)
+	for _, filePath := range filePaths {
+		m.refreshLock(filePath)
+	}
 }
 
-// autoRegeneratePDF automatically regenerates PDF when files change
-func (a *App) autoRegeneratePDF() {
-	if len(a.lastConvertedFiles) == 0 {
+// refreshLock checks the lock file of an input and publishes the change.
+// Releasing the lock regenerates at once if changes were held back while it was open.
+func (m *fileMonitor) refreshLock(filePath string) {
+	lockPath, editing := findOfficeLock(m.fs, filePath)
+	owner := ""
+	if editing {
+		owner = readLockOwner(m.fs, lockPath)
+	}
+	if !m.locks.Update(filePath, editing, owner) {
 		return
 	}
 
-	// Check if all files still exist
-	validFiles := []string{}
-	for _, filePath := range a.lastConvertedFiles {
-		if _, err := os.Stat(filePath); err == nil {
-			validFiles = append(validFiles, filePath)
+	m.emitter.emit(eventFileEditing, FileEditingEvent{File: filePath, Editing: editing, Owner: owner})
+
+	if !editing {
+		if operation, held := m.lockedDebouncer.Cancel(filePath); held {
+			m.debouncer.Trigger(filePath, operation)
+		}
+	}
+}
+
+// applyRename moves a renamed input to its new path and tells the frontend
+func (m *fileMonitor) applyRename(oldPath, newPath string) {
+	m.mu.Lock()
+	for i, inputPath := range m.inputs {
+		if filepath.Clean(inputPath) == oldPath {
+			m.inputs[i] = newPath
+		}
+	}
+	for filePath, snapshot := range m.polled {
+		if filepath.Clean(filePath) == oldPath {
+			m.polled[newPath] = snapshot
+			delete(m.polled, filePath)
+		}
+	}
+	dependencies := make(map[string][]string, len(m.dependencies))
+	for filePath, links := range m.dependencies {
+		if filepath.Clean(filePath) == oldPath {
+			filePath = newPath
 		}
+		dependencies[filePath] = links
+	}
+	m.setDependenciesLocked(dependencies)
+	inputs := append([]string(nil), m.inputs...)
+	watched := append([]string(nil), m.inputs...)
+	for _, links := range m.dependencies {
+		watched = append(watched, links...)
 	}
+	m.mu.Unlock()
 
-	if len(validFiles) == 0 {
-		return
+	for _, err := range m.dirs.SetFiles(watched) {
+		m.warn(err.Error())
 	}
+	m.refreshLocks(inputs)
+	m.renamed(oldPath, newPath)
 
-	// Re-convert with same sheet selections
-	_, err := a.ConvertToPDF(validFiles, a.lastConvertedSheets)
-	if err != nil {
-		runtime.EventsEmit(a.ctx, "conversion:error", map[string]interface{}{
-			"message": "Auto-update failed: " + err.Error(),
-		})
+	m.emitter.emit(eventFileRenamed, FileRenamedEvent{OldPath: oldPath, NewPath: newPath})
+
+	// Page titles follow the file name, so rebuild
+	if m.AutoUpdate() {
+		m.regen.Request()
 	}
 }
 
-// recordFileModTimes records the modification times of files
-func (a *App) recordFileModTimes(filePaths []string) {
-	a.fileModTimes = make(map[string]time.Time)
-	for _, filePath := range filePaths {
-		if info, err := os.Stat(filePath); err == nil {
-			a.fileModTimes[filePath] = info.ModTime()
+// StartPolling starts polling the inputs on network mounts at the configured interval
+func (m *fileMonitor) StartPolling() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextInterval = m.interval
+	m.schedulePollLocked()
+}
+
+// SetPollingInterval changes the polling interval; a running poll picks it up at once
+func (m *fileMonitor) SetPollingInterval(interval time.Duration) error {
+	if interval < minPollingInterval || interval > maxPollingInterval {
+		return fmt.Errorf("polling interval must be between %v and %v", minPollingInterval, maxPollingInterval)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.interval = interval
+	if m.pollTimer != nil {
+		m.nextInterval = interval
+		m.schedulePollLocked()
+	}
+	return nil
+}
+
+// PollingInterval returns the configured polling interval
+func (m *fileMonitor) PollingInterval() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.interval
+}
+
+// schedulePollLocked schedules the next poll if any input needs polling; m.mu must be held
+func (m *fileMonitor) schedulePollLocked() {
+	if m.pollTimer != nil {
+		m.pollTimer.Stop()
+		m.pollTimer = nil
+	}
+	if len(m.polled) == 0 {
+		return
+	}
+	select {
+	case <-m.done:
+		return
+	default:
+	}
+	m.pollTimer = m.clock.AfterFunc(m.nextInterval, m.pollTick)
+}
+
+// pollTick polls once and schedules the next poll, backing off while nothing changes
+func (m *fileMonitor) pollTick() {
+	changed := m.poll()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if changed {
+		m.nextInterval = m.interval
+	} else {
+		m.nextInterval *= 2
+		if m.nextInterval > maxPollingInterval {
+			m.nextInterval = maxPollingInterval
 		}
 	}
+	m.schedulePollLocked()
 }
 
-// startPolling starts polling for file changes as backup
-func (a *App) startPolling() {
-	if a.pollingTicker != nil {
-		a.pollingTicker.Stop()
+// poll checks the polled inputs against what was last seen and reports whether any changed
+func (m *fileMonitor) poll() bool {
+	if !m.AutoUpdate() {
+		return false
+	}
+
+	m.mu.Lock()
+	last := make(map[string]fileSnapshot, len(m.polled))
+	for filePath, snapshot := range m.polled {
+		last[filePath] = snapshot
 	}
+	m.polls++
+	hashAll := m.polls%pollHashEvery == 0
+	m.mu.Unlock()
+
+	var modified []string
+	for filePath, previous := range last {
+		// Only stat files whose size and time already rule out a change
+		info, err := m.fs.Stat(filePath)
+		if err != nil {
+			continue
+		}
+		if info.Size() == previous.size && info.ModTime().Equal(previous.modTime) && previous.settled() && !hashAll {
+			continue
+		}
+
+		current, err := m.snapshot(filePath)
+		if err != nil {
+			continue
+		}
+
+		m.mu.Lock()
+		if _, exists := m.polled[filePath]; exists {
+			m.polled[filePath] = current
+		}
+		m.mu.Unlock()
 
-	// Poll every 2 seconds
-	a.pollingTicker = time.NewTicker(2 * time.Second)
+		// A touched file with the same content needs no regeneration
+		if current.hash != previous.hash {
+			modified = append(modified, filePath)
+		}
+	}
 
-	go func() {
-		for range a.pollingTicker.C {
-			a.checkFileModifications()
+	// Coalesces with the file system events of the same save
+	for _, filePath := range modified {
+		if m.isInput(filePath) {
+			m.queueChange(filePath, "MODIFIED (polling)", "")
 		}
-	}()
+		for _, inputPath := range m.dependentsOf(filePath) {
+			m.queueChange(inputPath, "MODIFIED (polling)", filePath)
+		}
+	}
+	return len(modified) > 0
+}
+
+// snapshot reads the size, modification time and content hash of filePath
+func (m *fileMonitor) snapshot(filePath string) (fileSnapshot, error) {
+	info, err := m.fs.Stat(filePath)
+	if err != nil {
+		return fileSnapshot{}, err
+	}
+
+	file, err := m.fs.Open(filePath)
+	if err != nil {
+		return fileSnapshot{}, err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return fileSnapshot{}, err
+	}
+	return fileSnapshot{size: info.Size(), modTime: info.ModTime(), hash: hex.EncodeToString(h.Sum(nil)), hashed: m.clock.Now()}, nil
 }
 
-// checkFileModifications checks if any watched files have been modified
-func (a *App) checkFileModifications() {
-	if !a.autoUpdateEnabled || len(a.lastConvertedFiles) == 0 {
+// SetAutoUpdateEnabled enables or disables automatic PDF updates
+func (a *App) SetAutoUpdateEnabled(enabled bool) {
+	if _, err := a.changeSettings(func(s *Settings) { s.AutoUpdate = enabled }); err != nil {
+		runtime.LogWarning(a.ctx, err.Error())
+	}
+}
+
+// GetAutoUpdateEnabled returns current auto-update status
+func (a *App) GetAutoUpdateEnabled() bool {
+	return a.currentSettings().AutoUpdate
+}
+
+// SetPollingInterval sets how often inputs on network shares are checked, in milliseconds
+func (a *App) SetPollingInterval(milliseconds int) error {
+	_, err := a.changeSettings(func(s *Settings) { s.PollingIntervalMs = milliseconds })
+	return err
+}
+
+// GetPollingInterval returns how often inputs on network shares are checked, in milliseconds
+func (a *App) GetPollingInterval() int {
+	return a.currentSettings().PollingIntervalMs
+}
+
+// autoRegeneratePDF automatically regenerates PDF when files change
+func (a *App) autoRegeneratePDF(changed []string) {
+	// The last conversion is replaced by conversions from the GUI and the API under the same lock
+	a.conversionMu.Lock()
+	defer a.conversionMu.Unlock()
+
+	if len(a.lastConvertedFiles) == 0 {
 		return
 	}
 
-	hasChanges := false
+	// Check if all files still exist
+	validFiles := []string{}
 	for _, filePath := range a.lastConvertedFiles {
-		if info, err := os.Stat(filePath); err == nil {
-			if lastModTime, exists := a.fileModTimes[filePath]; exists {
-				if info.ModTime().After(lastModTime) {
-					hasChanges = true
-					a.fileModTimes[filePath] = info.ModTime()
-
-					runtime.EventsEmit(a.ctx, "file-changed", map[string]interface{}{
-						"file":      filePath,
-						"operation": "MODIFIED (polling)",
-					})
-					break
-				}
-			}
+		if _, err := os.Stat(filePath); err == nil {
+			validFiles = append(validFiles, filePath)
 		}
 	}
 
-	if hasChanges {
-		go a.autoRegeneratePDF()
+	if len(validFiles) == 0 {
+		return
+	}
+
+	// Follow sheets renamed or deleted in the changed workbooks
+	a.reconcileConvertedSheets(changed)
+
+	// Re-convert the changed inputs with the same sheet selections and merge again
+	opts := a.currentBundleOptions(validFiles, a.lastConvertedSheets)
+	opts.Changed = changed
+	_, _, err := a.convertBundleLocked(opts)
+	if err != nil {
+		a.emit(eventConversionError, ConversionErrorEvent{Message: "Auto-update failed: " + err.Error()})
 	}
 }
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// eventSource delivers file system events for the directories added to it
+type eventSource interface {
+	Events() <-chan fsnotify.Event
+	Errors() <-chan error
+	Add(name string) error
+	Remove(name string) error
+	Close() error
+}
+
+// clock tells the time and schedules callbacks
+type clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) clockTimer
+}
+
+// clockTimer is a callback scheduled by a clock
+type clockTimer interface {
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// fileStater reads file metadata, small files such as Office lock files, and file contents for hashing
+type fileStater interface {
+	Stat(name string) (os.FileInfo, error)
+	ReadFile(name string) ([]byte, error)
+	Open(name string) (io.ReadCloser, error)
+}
+
+// eventEmitter publishes events to the frontend and API clients
+type eventEmitter interface {
+	emit(eventType string, data interface{})
+}
+
+// fsnotifySource is the eventSource of a real fsnotify watcher
+type fsnotifySource struct {
+	watcher *fsnotify.Watcher
+}
+
+// newFsnotifySource creates a watcher on the local file system
+func newFsnotifySource() (*fsnotifySource, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &fsnotifySource{watcher: watcher}, nil
+}
+
+func (s *fsnotifySource) Events() <-chan fsnotify.Event { return s.watcher.Events }
+func (s *fsnotifySource) Errors() <-chan error          { return s.watcher.Errors }
+func (s *fsnotifySource) Add(name string) error         { return s.watcher.Add(name) }
+func (s *fsnotifySource) Remove(name string) error      { return s.watcher.Remove(name) }
+func (s *fsnotifySource) Close() error                  { return s.watcher.Close() }
+
+// pollingOnlySource stands in when no file system watcher is available; changes are found by polling
+type pollingOnlySource struct{}
+
+func (pollingOnlySource) Events() <-chan fsnotify.Event { return nil }
+func (pollingOnlySource) Errors() <-chan error          { return nil }
+func (pollingOnlySource) Add(string) error              { return nil }
+func (pollingOnlySource) Remove(string) error           { return nil }
+func (pollingOnlySource) Close() error                  { return nil }
+
+// realClock is the system clock
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) AfterFunc(d time.Duration, f func()) clockTimer { return time.AfterFunc(d, f) }
+
+// osFS reads the local file system
+type osFS struct{}
+
+func (osFS) Stat(name string) (os.FileInfo, error)   { return os.Stat(name) }
+func (osFS) ReadFile(name string) ([]byte, error)    { return os.ReadFile(name) }
+func (osFS) Open(name string) (io.ReadCloser, error) { return os.Open(name) }
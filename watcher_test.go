@@ -0,0 +1,744 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// waitTimeout bounds waits for work the monitor hands to goroutines
+const waitTimeout = 2 * time.Second
+
+// fakeClock is a manual clock; timers fire only when the test advances it
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock  *fakeClock
+	when   time.Time
+	f      func()
+	active bool
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) clockTimer {
+	c.mu.Lock()
+	t := &fakeTimer{clock: c, when: c.now.Add(d), f: f, active: true}
+	c.timers = append(c.timers, t)
+	c.mu.Unlock()
+	return t
+}
+
+// Advance moves the clock forward, firing due timers in order
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		var next *fakeTimer
+		for _, t := range c.timers {
+			if t.active && !t.when.After(end) && (next == nil || t.when.Before(next.when)) {
+				next = t
+			}
+		}
+		if next == nil {
+			c.now = end
+			c.mu.Unlock()
+			return
+		}
+		c.now = next.when
+		next.active = false
+		c.mu.Unlock()
+
+		next.f()
+	}
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.active
+	t.active = false
+	return active
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.active
+	t.when = t.clock.now.Add(d)
+	t.active = true
+	return active
+}
+
+// fakeSource records the watched directories
+type fakeSource struct {
+	mu     sync.Mutex
+	dirs   map[string]bool
+	events chan fsnotify.Event
+	errors chan error
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{
+		dirs:   make(map[string]bool),
+		events: make(chan fsnotify.Event),
+		errors: make(chan error),
+	}
+}
+
+func (s *fakeSource) Events() <-chan fsnotify.Event { return s.events }
+func (s *fakeSource) Errors() <-chan error          { return s.errors }
+func (s *fakeSource) Close() error                  { return nil }
+
+func (s *fakeSource) Add(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dirs[name] = true
+	return nil
+}
+
+func (s *fakeSource) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.dirs, name)
+	return nil
+}
+
+func (s *fakeSource) watching(dir string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dirs[dir]
+}
+
+// fakeFS is an in-memory file system
+type fakeFS struct {
+	mu    sync.Mutex
+	files map[string]*fakeFile
+	opens map[string]int // Times each file was opened for reading its content
+}
+
+type fakeFile struct {
+	name    string
+	data    []byte
+	modTime time.Time
+}
+
+func (f *fakeFile) Name() string       { return f.name }
+func (f *fakeFile) Size() int64        { return int64(len(f.data)) }
+func (f *fakeFile) Mode() fs.FileMode  { return 0644 }
+func (f *fakeFile) ModTime() time.Time { return f.modTime }
+func (f *fakeFile) IsDir() bool        { return false }
+func (f *fakeFile) Sys() interface{}   { return nil }
+
+func newFakeFS() *fakeFS {
+	return &fakeFS{files: make(map[string]*fakeFile), opens: make(map[string]int)}
+}
+
+func (f *fakeFS) Write(name string, data string, modTime time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.files[name] = &fakeFile{name: filepath.Base(name), data: []byte(data), modTime: modTime}
+}
+
+func (f *fakeFS) Rename(oldName, newName string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file := *f.files[oldName]
+	file.name = filepath.Base(newName)
+	f.files[newName] = &file
+	delete(f.files, oldName)
+}
+
+func (f *fakeFS) Remove(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.files, name)
+}
+
+func (f *fakeFS) Stat(name string) (os.FileInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, exists := f.files[name]
+	if !exists {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	copied := *file
+	return &copied, nil
+}
+
+func (f *fakeFS) Open(name string) (io.ReadCloser, error) {
+	data, err := f.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.opens[name]++
+	f.mu.Unlock()
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Opens returns how often name was opened
+func (f *fakeFS) Opens(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.opens[name]
+}
+
+func (f *fakeFS) ReadFile(name string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, exists := f.files[name]
+	if !exists {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), file.data...), nil
+}
+
+// recordingEmitter keeps the emitted events
+type recordingEmitter struct {
+	mu     sync.Mutex
+	events []AppEvent
+}
+
+func (e *recordingEmitter) emit(eventType string, data interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, AppEvent{Type: eventType, Data: data})
+}
+
+func (e *recordingEmitter) ofType(eventType string) []interface{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var data []interface{}
+	for _, event := range e.events {
+		if event.Type == eventType {
+			data = append(data, event.Data)
+		}
+	}
+	return data
+}
+
+// monitorTest wires a monitor to fakes and records its regenerations
+type monitorTest struct {
+	t       *testing.T
+	clock   *fakeClock
+	source  *fakeSource
+	fs      *fakeFS
+	emitter *recordingEmitter
+	monitor *fileMonitor
+	runs    chan []string
+	renamed [][2]string
+	links   map[string][]string // Linked files per input
+}
+
+// newMonitorTest creates a monitor with file system events; files under "share" are on a network mount
+func newMonitorTest(t *testing.T) *monitorTest {
+	source := newFakeSource()
+	mt := newMonitorTestWithSource(t, source)
+	mt.source = source
+	return mt
+}
+
+func newMonitorTestWithSource(t *testing.T, source eventSource) *monitorTest {
+	mt := &monitorTest{
+		t:       t,
+		clock:   newFakeClock(),
+		fs:      newFakeFS(),
+		emitter: &recordingEmitter{},
+		runs:    make(chan []string, 16),
+		links:   make(map[string][]string),
+	}
+	mt.monitor = newFileMonitor(source, mt.clock, mt.fs, mt.emitter)
+	mt.monitor.links = func(filePath string) []string {
+		return mt.links[filePath]
+	}
+	mt.monitor.remote = func(filePath string) bool {
+		return strings.HasPrefix(filePath, "share"+string(filepath.Separator))
+	}
+	mt.monitor.regenerate = func(changed []string) {
+		sort.Strings(changed)
+		mt.runs <- changed
+	}
+	mt.monitor.renamed = func(oldPath, newPath string) {
+		mt.renamed = append(mt.renamed, [2]string{oldPath, newPath})
+	}
+	mt.monitor.warn = func(message string) {
+		t.Errorf("unexpected warning: %s", message)
+	}
+	t.Cleanup(mt.monitor.Stop)
+	return mt
+}
+
+// event delivers a file system event
+func (mt *monitorTest) event(op fsnotify.Op, name string) {
+	mt.monitor.handleEvent(fsnotify.Event{Name: name, Op: op})
+}
+
+// expectRun waits for a regeneration and checks the changed inputs
+func (mt *monitorTest) expectRun(want ...string) {
+	mt.t.Helper()
+	select {
+	case changed := <-mt.runs:
+		if want == nil {
+			want = []string{}
+		}
+		if !reflect.DeepEqual(changed, want) {
+			mt.t.Fatalf("regenerated with %v, want %v", changed, want)
+		}
+	case <-time.After(waitTimeout):
+		mt.t.Fatalf("no regeneration, want one with %v", want)
+	}
+}
+
+// expectNoRun checks that no regeneration has been started
+func (mt *monitorTest) expectNoRun() {
+	mt.t.Helper()
+	select {
+	case changed := <-mt.runs:
+		mt.t.Fatalf("unexpected regeneration with %v", changed)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestMonitorDebouncesBurstPerFile(t *testing.T) {
+	mt := newMonitorTest(t)
+	input := filepath.Join("docs", "report.xlsx")
+	mt.fs.Write(input, "v1", mt.clock.Now())
+	mt.monitor.SetInputs([]string{input})
+
+	if !mt.source.watching("docs") {
+		t.Fatal("input directory is not watched")
+	}
+
+	// One save produces several events in quick succession
+	for i := 0; i < 3; i++ {
+		mt.event(fsnotify.Write, input)
+		mt.clock.Advance(100 * time.Millisecond)
+	}
+	mt.clock.Advance(autoUpdateDebounce - 100*time.Millisecond - time.Millisecond)
+	mt.expectNoRun()
+
+	mt.clock.Advance(time.Millisecond)
+	mt.expectRun(input)
+
+	if changed := mt.emitter.ofType(eventFileChanged); len(changed) != 1 {
+		t.Fatalf("got %d file-changed events, want 1", len(changed))
+	}
+}
+
+func TestMonitorIgnoresUnrelatedFilesAndDisabledAutoUpdate(t *testing.T) {
+	mt := newMonitorTest(t)
+	input := filepath.Join("docs", "report.xlsx")
+	mt.fs.Write(input, "v1", mt.clock.Now())
+	mt.monitor.SetInputs([]string{input})
+
+	mt.event(fsnotify.Write, filepath.Join("docs", "other.xlsx"))
+	mt.clock.Advance(time.Minute)
+	mt.expectNoRun()
+
+	mt.monitor.SetAutoUpdate(false)
+	mt.event(fsnotify.Write, input)
+	mt.clock.Advance(time.Minute)
+	mt.expectNoRun()
+}
+
+func TestMonitorFoldsChangesDuringRunIntoOneFollowUp(t *testing.T) {
+	mt := newMonitorTest(t)
+	first := filepath.Join("docs", "a.xlsx")
+	second := filepath.Join("docs", "b.docx")
+	mt.fs.Write(first, "a", mt.clock.Now())
+	mt.fs.Write(second, "b", mt.clock.Now())
+	mt.monitor.SetInputs([]string{first, second})
+
+	// Hold the first run until the other changes have settled
+	started := make(chan []string)
+	release := make(chan struct{})
+	mt.monitor.regenerate = func(changed []string) {
+		sort.Strings(changed)
+		select {
+		case started <- changed:
+			<-release
+		default:
+			mt.runs <- changed
+		}
+	}
+
+	mt.event(fsnotify.Write, first)
+	mt.clock.Advance(autoUpdateDebounce)
+	select {
+	case changed := <-started:
+		if !reflect.DeepEqual(changed, []string{first}) {
+			t.Fatalf("first run with %v, want %v", changed, []string{first})
+		}
+	case <-time.After(waitTimeout):
+		t.Fatal("first run did not start")
+	}
+
+	mt.event(fsnotify.Write, second)
+	mt.clock.Advance(autoUpdateDebounce)
+	mt.event(fsnotify.Write, first)
+	mt.clock.Advance(autoUpdateDebounce)
+	close(release)
+
+	mt.expectRun(first, second)
+	mt.expectNoRun()
+}
+
+func TestMonitorDefersChangesWhileFileIsLocked(t *testing.T) {
+	mt := newMonitorTest(t)
+	input := filepath.Join("docs", "report.xlsx")
+	lockPath := filepath.Join("docs", ".~lock.report.xlsx#")
+	mt.fs.Write(input, "v1", mt.clock.Now())
+	mt.fs.Write(lockPath, "Taro,host,taro,01.01.2026 09:00,file:///profile;", mt.clock.Now())
+	mt.monitor.SetInputs([]string{input})
+
+	editing := mt.emitter.ofType(eventFileEditing)
+	if want := (FileEditingEvent{File: input, Editing: true, Owner: "Taro"}); len(editing) != 1 || editing[0] != want {
+		t.Fatalf("editing events %v, want [%v]", editing, want)
+	}
+
+	// Saves while the file is open wait for the longer quiet period
+	mt.event(fsnotify.Write, input)
+	mt.clock.Advance(autoUpdateDebounce)
+	mt.expectNoRun()
+	mt.clock.Advance(lockQuietPeriod - autoUpdateDebounce)
+	mt.expectRun(input)
+
+	// Closing the file releases a held change after the normal debounce
+	mt.event(fsnotify.Write, input)
+	mt.clock.Advance(time.Second)
+	mt.fs.Remove(lockPath)
+	mt.event(fsnotify.Remove, lockPath)
+	mt.expectNoRun()
+	mt.clock.Advance(autoUpdateDebounce)
+	mt.expectRun(input)
+
+	editing = mt.emitter.ofType(eventFileEditing)
+	if want := (FileEditingEvent{File: input}); len(editing) != 2 || editing[1] != want {
+		t.Fatalf("editing events %v, want release %v last", editing, want)
+	}
+	if owners := mt.monitor.locks.Owners(); len(owners) != 0 {
+		t.Fatalf("locks %v remain after release", owners)
+	}
+}
+
+func TestMonitorReadsOfficeLockOwner(t *testing.T) {
+	mt := newMonitorTest(t)
+	input := filepath.Join("docs", "budget.xlsx")
+	mt.fs.Write(input, "v1", mt.clock.Now())
+	mt.monitor.SetInputs([]string{input})
+
+	// Office lock file: ANSI name, then the UTF-16 name at offset 54
+	data := make([]byte, 56+2*4)
+	data[0] = 4
+	copy(data[1:], "yama")
+	data[54] = 4
+	units := []uint16{0x5c71, 0x7530, 0x592a, 0x90ce}
+	for i, u := range units {
+		data[56+2*i] = byte(u)
+		data[57+2*i] = byte(u >> 8)
+	}
+	lockPath := filepath.Join("docs", "~$budget.xlsx")
+	mt.fs.Write(lockPath, string(data), mt.clock.Now())
+	mt.event(fsnotify.Create, lockPath)
+
+	if owners := mt.monitor.locks.Owners(); owners[input] != "山田太郎" {
+		t.Fatalf("owner %q, want %q", owners[input], "山田太郎")
+	}
+	mt.clock.Advance(time.Minute)
+	mt.expectNoRun()
+}
+
+func TestMonitorFollowsRenamedInput(t *testing.T) {
+	mt := newMonitorTest(t)
+	oldPath := filepath.Join("docs", "draft.docx")
+	newPath := filepath.Join("archive", "final.docx")
+	mt.fs.Write(oldPath, "content", mt.clock.Now())
+	mt.monitor.SetInputs([]string{oldPath})
+
+	mt.fs.Rename(oldPath, newPath)
+	mt.event(fsnotify.Rename, oldPath)
+	mt.clock.Advance(100 * time.Millisecond)
+	mt.event(fsnotify.Create, newPath)
+
+	if inputs := mt.monitor.Inputs(); !reflect.DeepEqual(inputs, []string{newPath}) {
+		t.Fatalf("inputs %v, want %v", inputs, []string{newPath})
+	}
+	if want := [][2]string{{oldPath, newPath}}; !reflect.DeepEqual(mt.renamed, want) {
+		t.Fatalf("renamed hook got %v, want %v", mt.renamed, want)
+	}
+	renamed := mt.emitter.ofType(eventFileRenamed)
+	if want := (FileRenamedEvent{OldPath: oldPath, NewPath: newPath}); len(renamed) != 1 || renamed[0] != want {
+		t.Fatalf("renamed events %v, want [%v]", renamed, want)
+	}
+	if !mt.source.watching("archive") || mt.source.watching("docs") {
+		t.Fatal("watch did not move to the new directory")
+	}
+	mt.expectRun()
+
+	// The pending rename event of the old name must not fire a change
+	mt.clock.Advance(time.Minute)
+	mt.expectNoRun()
+}
+
+func TestMonitorTreatsTempSaveAsChange(t *testing.T) {
+	mt := newMonitorTest(t)
+	input := filepath.Join("docs", "report.xlsx")
+	tempPath := filepath.Join("docs", "4F2A1B3C")
+	mt.fs.Write(input, "v1", mt.clock.Now())
+	mt.monitor.SetInputs([]string{input})
+
+	// Excel writes a temporary file, renames the original away and the new file into place
+	mt.fs.Write(tempPath, "v2 longer", mt.clock.Now().Add(time.Second))
+	mt.event(fsnotify.Create, tempPath)
+	mt.fs.Rename(input, filepath.Join("docs", "report.xlsx~RF1.TMP"))
+	mt.event(fsnotify.Rename, input)
+	mt.event(fsnotify.Create, filepath.Join("docs", "report.xlsx~RF1.TMP"))
+	mt.fs.Rename(tempPath, input)
+	mt.event(fsnotify.Rename, tempPath)
+	mt.event(fsnotify.Create, input)
+
+	mt.clock.Advance(autoUpdateDebounce)
+	mt.expectRun(input)
+
+	if renamed := mt.emitter.ofType(eventFileRenamed); len(renamed) != 0 {
+		t.Fatalf("save reported as rename: %v", renamed)
+	}
+	if inputs := mt.monitor.Inputs(); !reflect.DeepEqual(inputs, []string{input}) {
+		t.Fatalf("inputs %v, want %v", inputs, []string{input})
+	}
+}
+
+func TestMonitorRegeneratesDependentsOfLinkedFiles(t *testing.T) {
+	mt := newMonitorTest(t)
+	summary := filepath.Join("docs", "summary.xlsx")
+	other := filepath.Join("docs", "other.docx")
+	source := filepath.Join("data", "sales.xlsx")
+	mt.fs.Write(summary, "s1", mt.clock.Now())
+	mt.fs.Write(other, "o1", mt.clock.Now())
+	mt.fs.Write(source, "d1", mt.clock.Now())
+	mt.links[summary] = []string{source}
+	mt.monitor.SetInputs([]string{summary, other})
+
+	if !mt.source.watching("data") {
+		t.Fatal("directory of the linked file is not watched")
+	}
+	dependencies := mt.emitter.ofType(eventFileDependencies)
+	if len(dependencies) != 1 || !reflect.DeepEqual(dependencies[0], FileDependenciesEvent{File: summary, Dependencies: []string{source}}) {
+		t.Fatalf("dependency events %v, want one for %s", dependencies, summary)
+	}
+
+	// Only the input linking to the changed file is reconverted
+	mt.event(fsnotify.Write, source)
+	mt.clock.Advance(autoUpdateDebounce)
+	mt.expectRun(summary)
+
+	changed := mt.emitter.ofType(eventFileChanged)
+	if want := (FileChangedEvent{File: summary, Operation: "WRITE", Dependency: source}); len(changed) != 1 || changed[0] != want {
+		t.Fatalf("file-changed events %v, want [%v]", changed, want)
+	}
+
+	// Unchanged links are not announced again
+	mt.monitor.SetInputs([]string{summary, other})
+	if dependencies := mt.emitter.ofType(eventFileDependencies); len(dependencies) != 1 {
+		t.Fatalf("got %d dependency events, want 1", len(dependencies))
+	}
+}
+
+// polled returns the inputs the monitor polls
+func (mt *monitorTest) polled() []string {
+	mt.monitor.mu.Lock()
+	defer mt.monitor.mu.Unlock()
+	var polled []string
+	for filePath := range mt.monitor.polled {
+		polled = append(polled, filePath)
+	}
+	sort.Strings(polled)
+	return polled
+}
+
+// nextInterval returns the interval until the next poll
+func (mt *monitorTest) nextInterval() time.Duration {
+	mt.monitor.mu.Lock()
+	defer mt.monitor.mu.Unlock()
+	return mt.monitor.nextInterval
+}
+
+func TestMonitorPollsOnlyNetworkInputs(t *testing.T) {
+	mt := newMonitorTest(t)
+	local := filepath.Join("docs", "a.xlsx")
+	remote := filepath.Join("share", "b.xlsx")
+	mt.fs.Write(local, "a1", mt.clock.Now())
+	mt.fs.Write(remote, "b1", mt.clock.Now())
+	mt.monitor.SetInputs([]string{local, remote})
+	mt.monitor.StartPolling()
+
+	if polled := mt.polled(); !reflect.DeepEqual(polled, []string{remote}) {
+		t.Fatalf("polling %v, want %v", polled, []string{remote})
+	}
+
+	// No file system events arrive; only the network input is found by polling
+	mt.fs.Write(local, "a2", mt.clock.Now().Add(time.Second))
+	mt.fs.Write(remote, "b2", mt.clock.Now().Add(time.Second))
+	mt.clock.Advance(pollingInterval + autoUpdateDebounce)
+	mt.expectRun(remote)
+	mt.expectNoRun()
+
+	if changed := mt.emitter.ofType(eventFileChanged); len(changed) != 1 || changed[0].(FileChangedEvent).Operation != "MODIFIED (polling)" {
+		t.Fatalf("file-changed events %v, want one from polling", changed)
+	}
+}
+
+func TestMonitorPollsEverythingWithoutFileSystemEvents(t *testing.T) {
+	mt := newMonitorTestWithSource(t, pollingOnlySource{})
+	input := filepath.Join("docs", "report.xlsx")
+	mt.fs.Write(input, "v1", mt.clock.Now())
+	mt.monitor.SetInputs([]string{input})
+	mt.monitor.StartPolling()
+
+	mt.fs.Write(input, "v2", mt.clock.Now().Add(time.Second))
+	mt.clock.Advance(pollingInterval + autoUpdateDebounce)
+	mt.expectRun(input)
+}
+
+func TestMonitorPollingBacksOffWhileIdle(t *testing.T) {
+	mt := newMonitorTest(t)
+	input := filepath.Join("share", "report.xlsx")
+	mt.fs.Write(input, "v1", mt.clock.Now())
+	mt.monitor.SetInputs([]string{input})
+	mt.monitor.StartPolling()
+
+	for _, want := range []time.Duration{4 * time.Second, 8 * time.Second, 16 * time.Second, maxPollingInterval, maxPollingInterval} {
+		mt.clock.Advance(mt.nextInterval())
+		if got := mt.nextInterval(); got != want {
+			t.Fatalf("next poll in %v, want %v", got, want)
+		}
+	}
+
+	// A change brings the interval back to the configured one
+	mt.fs.Write(input, "v2", mt.clock.Now())
+	mt.clock.Advance(maxPollingInterval)
+	if got := mt.nextInterval(); got != pollingInterval {
+		t.Fatalf("next poll in %v after a change, want %v", got, pollingInterval)
+	}
+	mt.clock.Advance(autoUpdateDebounce)
+	mt.expectRun(input)
+}
+
+func TestMonitorPollingComparesContent(t *testing.T) {
+	mt := newMonitorTest(t)
+	input := filepath.Join("share", "report.xlsx")
+	modTime := mt.clock.Now()
+	mt.fs.Write(input, "v1", modTime)
+	mt.monitor.SetInputs([]string{input})
+	mt.monitor.StartPolling()
+
+	// Same size and modification time, as with coarse timestamps on network shares
+	mt.fs.Write(input, "v2", modTime)
+	mt.clock.Advance(pollingInterval + autoUpdateDebounce)
+	mt.expectRun(input)
+
+	// Touching the file without changing it does not regenerate
+	mt.fs.Write(input, "v2", modTime.Add(time.Minute))
+	mt.clock.Advance(time.Minute)
+	mt.expectNoRun()
+}
+
+func TestMonitorPollingHashesOnlyWhenMetadataChanges(t *testing.T) {
+	mt := newMonitorTest(t)
+	input := filepath.Join("share", "report.xlsx")
+	mt.fs.Write(input, "v1", mt.clock.Now().Add(-time.Hour))
+	mt.monitor.SetInputs([]string{input})
+	mt.monitor.StartPolling()
+	opens := mt.fs.Opens(input)
+
+	// Polls of an unchanged, settled file only stat it, until the periodic full check
+	for i := 1; i < pollHashEvery; i++ {
+		mt.clock.Advance(mt.nextInterval())
+	}
+	if got := mt.fs.Opens(input) - opens; got != 0 {
+		t.Fatalf("unchanged file read %d times, want 0", got)
+	}
+	mt.clock.Advance(mt.nextInterval())
+	if got := mt.fs.Opens(input) - opens; got != 1 {
+		t.Fatalf("file read %d times by the full check, want 1", got)
+	}
+
+	// A new modification time is confirmed by reading the file
+	mt.fs.Write(input, "v2", mt.clock.Now())
+	mt.clock.Advance(mt.nextInterval())
+	if got := mt.fs.Opens(input) - opens; got != 2 {
+		t.Fatalf("file read %d times after a change, want 2", got)
+	}
+	mt.clock.Advance(autoUpdateDebounce)
+	mt.expectRun(input)
+}
+
+func TestMonitorPollingCoalescesWithEvents(t *testing.T) {
+	mt := newMonitorTest(t)
+	input := filepath.Join("share", "report.xlsx")
+	mt.fs.Write(input, "v1", mt.clock.Now())
+	mt.monitor.SetInputs([]string{input})
+	mt.monitor.StartPolling()
+
+	// The event and the poll of the same save produce one regeneration
+	mt.clock.Advance(pollingInterval - 100*time.Millisecond)
+	mt.fs.Write(input, "v2", mt.clock.Now())
+	mt.event(fsnotify.Write, input)
+	mt.clock.Advance(100 * time.Millisecond)
+	mt.clock.Advance(autoUpdateDebounce)
+	mt.expectRun(input)
+	mt.expectNoRun()
+}
+
+func TestMonitorPollingInterval(t *testing.T) {
+	mt := newMonitorTest(t)
+	input := filepath.Join("share", "report.xlsx")
+	mt.fs.Write(input, "v1", mt.clock.Now())
+	mt.monitor.SetInputs([]string{input})
+	mt.monitor.StartPolling()
+
+	for _, interval := range []time.Duration{100 * time.Millisecond, time.Hour} {
+		if err := mt.monitor.SetPollingInterval(interval); err == nil {
+			t.Fatalf("interval %v accepted", interval)
+		}
+	}
+	if err := mt.monitor.SetPollingInterval(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got := mt.monitor.PollingInterval(); got != 5*time.Second {
+		t.Fatalf("interval %v, want 5s", got)
+	}
+
+	// The running poll uses the new interval at once
+	mt.fs.Write(input, "v2", mt.clock.Now())
+	mt.clock.Advance(pollingInterval + autoUpdateDebounce)
+	mt.expectNoRun()
+	mt.clock.Advance(5*time.Second - pollingInterval)
+	mt.expectRun(input)
+}
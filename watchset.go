@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+)
+
+// dirWatcher adds and removes watched directories, as fsnotify.Watcher and eventSource do
+type dirWatcher interface {
+	Add(name string) error
+	Remove(name string) error
+}
+
+// dirWatchSet is a reference-counted set of directories watched by a dirWatcher.
+// Every watched file holds one reference to its directory, so a directory stays
+// watched while any selected file in it remains.
+type dirWatchSet struct {
+	mu      sync.Mutex
+	watcher dirWatcher
+	refs    map[string]int  // Directory -> number of watched files in it
+	files   map[string]bool // Watched files, cleaned
+}
+
+// newDirWatchSet creates an empty set on top of watcher
+func newDirWatchSet(watcher dirWatcher) *dirWatchSet {
+	return &dirWatchSet{
+		watcher: watcher,
+		refs:    make(map[string]int),
+		files:   make(map[string]bool),
+	}
+}
+
+// SetFiles makes files the watched files, adding and removing directory watches for the difference.
+// Files whose directory cannot be watched are skipped and reported in the returned errors.
+func (s *dirWatchSet) SetFiles(files []string) []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wanted := make(map[string]bool)
+	for _, filePath := range files {
+		wanted[filepath.Clean(filePath)] = true
+	}
+
+	for filePath := range s.files {
+		if !wanted[filePath] {
+			s.releaseLocked(filepath.Dir(filePath))
+			delete(s.files, filePath)
+		}
+	}
+
+	var errs []error
+	for filePath := range wanted {
+		if s.files[filePath] {
+			continue
+		}
+		if err := s.acquireLocked(filepath.Dir(filePath)); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		s.files[filePath] = true
+	}
+	return errs
+}
+
+// acquireLocked adds a reference to dir, watching it on the first one; the caller must hold s.mu
+func (s *dirWatchSet) acquireLocked(dir string) error {
+	if s.refs[dir] == 0 {
+		if err := s.watcher.Add(dir); err != nil {
+			return fmt.Errorf("cannot watch %s: %v", dir, err)
+		}
+	}
+	s.refs[dir]++
+	return nil
+}
+
+// releaseLocked drops a reference to dir, unwatching it on the last one; the caller must hold s.mu
+func (s *dirWatchSet) releaseLocked(dir string) {
+	s.refs[dir]--
+	if s.refs[dir] <= 0 {
+		delete(s.refs, dir)
+		s.watcher.Remove(dir)
+	}
+}
@@ -0,0 +1,466 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/menu"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Workspace events
+const (
+	eventWorkspaceChanged       = "workspace:changed"          // Workspaces of the working directory changed
+	eventWorkspaceSwitchRequest = "workspace:switch-requested" // Menu asks the frontend to save its session and switch
+	eventWorkspaceCreateRequest = "workspace:create-requested" // Menu asks the frontend for the name of a new workspace
+)
+
+// defaultWorkspaceID is the workspace every directory starts with.
+// Its session is stored where sessions were stored before workspaces existed.
+const defaultWorkspaceID = "default"
+
+// legacyDefaultWorkspaceName is the name earlier versions stored for the default workspace.
+// The default workspace is now stored without a name and shown in the language of the settings.
+const legacyDefaultWorkspaceName = "標準"
+
+// maxRecentWorkspaces is how many workspaces the menu lists
+const maxRecentWorkspaces = 5
+
+// maxWorkspaceNameLength limits workspace names, in characters
+const maxWorkspaceNameLength = 64
+
+// Workspace is a named session of a directory with its own selection and output options
+type Workspace struct {
+	ID       string    `json:"id"`       // Stable ID used in state file names
+	Name     string    `json:"name"`     // Display name, unique in the directory; empty for the default workspace until renamed
+	Created  time.Time `json:"created"`  // When the workspace was created
+	LastUsed time.Time `json:"lastUsed"` // When the workspace was last switched to
+	Active   bool      `json:"active"`   // Whether this is the active workspace; set in listings
+}
+
+// workspaceIndex is the stored list of workspaces of a directory
+type workspaceIndex struct {
+	DirectoryPath string      `json:"directoryPath"` // Directory path
+	Active        string      `json:"active"`        // ID of the active workspace
+	Workspaces    []Workspace `json:"workspaces"`    // Workspaces in creation order
+}
+
+// WorkspaceChangedEvent is the payload of "workspace:changed"
+type WorkspaceChangedEvent struct {
+	Directory  string      `json:"directory"`
+	Active     string      `json:"active"`
+	Workspaces []Workspace `json:"workspaces"` // Most recently used first
+}
+
+// workspaceIndexName is the state file of the workspace list of a directory
+func workspaceIndexName(dirHash string) string {
+	return fmt.Sprintf("workspaces_%s.json", dirHash)
+}
+
+// workspaceSessionName is the state file of the session of a workspace
+func workspaceSessionName(dirHash, id string) string {
+	if id == defaultWorkspaceID {
+		return sessionStateName(dirHash)
+	}
+	return fmt.Sprintf("session_%s_%s.json", dirHash, id)
+}
+
+// newWorkspaceIndex returns the workspace list of a directory that has only its default workspace
+func newWorkspaceIndex(absPath string) workspaceIndex {
+	return workspaceIndex{
+		DirectoryPath: absPath,
+		Active:        defaultWorkspaceID,
+		Workspaces:    []Workspace{{ID: defaultWorkspaceID}},
+	}
+}
+
+// find returns the workspace with id, or nil
+func (idx *workspaceIndex) find(id string) *Workspace {
+	for i := range idx.Workspaces {
+		if idx.Workspaces[i].ID == id {
+			return &idx.Workspaces[i]
+		}
+	}
+	return nil
+}
+
+// unnamed reports whether ws is the default workspace still under its default name
+func (ws Workspace) unnamed() bool {
+	return ws.ID == defaultWorkspaceID && (ws.Name == "" || ws.Name == legacyDefaultWorkspaceName)
+}
+
+// isDefaultWorkspaceName reports whether name is the default workspace name in any language
+func isDefaultWorkspaceName(name string) bool {
+	for _, catalog := range messages {
+		if strings.EqualFold(catalog["workspace.default"], name) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkName validates a new name for the workspace id; other workspaces may not use it
+func (idx *workspaceIndex) checkName(name, id string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("workspace name cannot be empty")
+	}
+	if len([]rune(name)) > maxWorkspaceNameLength {
+		return "", fmt.Errorf("workspace name is longer than %d characters", maxWorkspaceNameLength)
+	}
+	for _, ws := range idx.Workspaces {
+		if ws.ID == id {
+			continue
+		}
+		// The unnamed default workspace takes its name in every language
+		if (ws.unnamed() && isDefaultWorkspaceName(name)) || strings.EqualFold(ws.Name, name) {
+			return "", fmt.Errorf("workspace %q already exists", name)
+		}
+	}
+	return name, nil
+}
+
+// recent returns the workspaces most recently used first, with the active one marked
+func (idx *workspaceIndex) recent() []Workspace {
+	workspaces := make([]Workspace, len(idx.Workspaces))
+	copy(workspaces, idx.Workspaces)
+	for i := range workspaces {
+		workspaces[i].Active = workspaces[i].ID == idx.Active
+	}
+	sort.SliceStable(workspaces, func(i, j int) bool {
+		return workspaces[i].LastUsed.After(workspaces[j].LastUsed)
+	})
+	return workspaces
+}
+
+// workspaceDirectory normalizes dirPath and returns it with its hash
+func (a *App) workspaceDirectory(dirPath string) (string, string, error) {
+	if dirPath == "" {
+		return "", "", fmt.Errorf("no working directory set")
+	}
+	absPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		absPath = dirPath
+	}
+	return absPath, a.createDirectoryHash(absPath), nil
+}
+
+// loadWorkspaces returns the workspace list of dirPath
+func (a *App) loadWorkspaces(dirPath string) (workspaceIndex, error) {
+	absPath, dirHash, err := a.workspaceDirectory(dirPath)
+	if err != nil {
+		return workspaceIndex{}, err
+	}
+
+	var idx workspaceIndex
+	exists, err := a.state.Load(workspaceIndexName(dirHash), &idx)
+	if err != nil {
+		return workspaceIndex{}, fmt.Errorf("failed to read workspaces: %v", err)
+	}
+	if !exists || idx.find(idx.Active) == nil {
+		return newWorkspaceIndex(absPath), nil
+	}
+	return idx, nil
+}
+
+// hasWorkspaceList reports whether a workspace list is stored for the directory hash
+func (a *App) hasWorkspaceList(dirHash string) bool {
+	entries, err := a.state.List(workspaceIndexName(dirHash))
+	return err == nil && len(entries) > 0
+}
+
+// updateWorkspaces changes the workspace list of dirPath and publishes the result
+func (a *App) updateWorkspaces(dirPath string, update func(idx *workspaceIndex, dirHash string) error) (workspaceIndex, error) {
+	absPath, dirHash, err := a.workspaceDirectory(dirPath)
+	if err != nil {
+		return workspaceIndex{}, err
+	}
+
+	var idx workspaceIndex
+	err = a.state.Update(workspaceIndexName(dirHash), &idx, func(exists bool) error {
+		if !exists || idx.find(idx.Active) == nil {
+			idx = newWorkspaceIndex(absPath)
+		}
+		return update(&idx, dirHash)
+	})
+	if err != nil {
+		return workspaceIndex{}, err
+	}
+
+	a.workspacesChanged(idx)
+	return idx, nil
+}
+
+// isWorkingDirectory reports whether absPath is the working directory of the window
+func (a *App) isWorkingDirectory(absPath string) bool {
+	return a.initialDir != "" && a.createDirectoryHash(a.initialDir) == a.createDirectoryHash(absPath)
+}
+
+// activeWorkspaceSession returns the state file of the active workspace session of dirPath
+func (a *App) activeWorkspaceSession(dirHash, dirPath string) (string, string) {
+	idx, err := a.loadWorkspaces(dirPath)
+	if err != nil {
+		return defaultWorkspaceID, sessionStateName(dirHash)
+	}
+	return idx.Active, workspaceSessionName(dirHash, idx.Active)
+}
+
+// ListWorkspaces returns the workspaces of the working directory, most recently used first
+func (a *App) ListWorkspaces() ([]Workspace, error) {
+	idx, err := a.loadWorkspaces(a.initialDir)
+	if err != nil {
+		return nil, err
+	}
+	return a.localizeWorkspaces(idx.recent()), nil
+}
+
+// localizeWorkspaces names the unnamed default workspace in the language of the settings
+func (a *App) localizeWorkspaces(workspaces []Workspace) []Workspace {
+	for i := range workspaces {
+		if workspaces[i].unnamed() {
+			workspaces[i].Name = a.text("workspace.default")
+		}
+	}
+	return workspaces
+}
+
+// CreateWorkspace adds an empty workspace to the working directory
+func (a *App) CreateWorkspace(name string) (*Workspace, error) {
+	return a.addWorkspace(name, "")
+}
+
+// DuplicateWorkspace adds a workspace with a copy of the session of the workspace id
+func (a *App) DuplicateWorkspace(id, name string) (*Workspace, error) {
+	if id == "" {
+		return nil, fmt.Errorf("workspace ID cannot be empty")
+	}
+	return a.addWorkspace(name, id)
+}
+
+// addWorkspace adds a workspace, copying the session of sourceID unless it is empty
+func (a *App) addWorkspace(name, sourceID string) (*Workspace, error) {
+	id, err := randomHex(4)
+	if err != nil {
+		return nil, err
+	}
+
+	_, dirHash, err := a.workspaceDirectory(a.initialDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy the session first; the workspace appears in the list only once it is complete
+	copied := false
+	if sourceID != "" {
+		idx, err := a.loadWorkspaces(a.initialDir)
+		if err != nil {
+			return nil, err
+		}
+		if idx.find(sourceID) == nil {
+			return nil, fmt.Errorf("workspace not found: %s", sourceID)
+		}
+
+		var session DirectorySessionCache
+		exists, err := a.state.Load(workspaceSessionName(dirHash, sourceID), &session)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session: %v", err)
+		}
+		if exists {
+			session.Workspace = id
+			session.LastUpdated = time.Now()
+			if err := a.state.Save(workspaceSessionName(dirHash, id), session); err != nil {
+				return nil, fmt.Errorf("failed to write session: %v", err)
+			}
+			copied = true
+		}
+	}
+
+	var created Workspace
+	_, err = a.updateWorkspaces(a.initialDir, func(idx *workspaceIndex, _ string) error {
+		name, err := idx.checkName(name, "")
+		if err != nil {
+			return err
+		}
+		if sourceID != "" && idx.find(sourceID) == nil {
+			return fmt.Errorf("workspace not found: %s", sourceID)
+		}
+
+		created = Workspace{ID: id, Name: name, Created: time.Now()}
+		idx.Workspaces = append(idx.Workspaces, created)
+		return nil
+	})
+	if err != nil {
+		if copied {
+			a.state.Remove(workspaceSessionName(dirHash, id))
+		}
+		return nil, err
+	}
+	return &created, nil
+}
+
+// RenameWorkspace changes the name of the workspace id
+func (a *App) RenameWorkspace(id, name string) error {
+	_, err := a.updateWorkspaces(a.initialDir, func(idx *workspaceIndex, _ string) error {
+		ws := idx.find(id)
+		if ws == nil {
+			return fmt.Errorf("workspace not found: %s", id)
+		}
+		name, err := idx.checkName(name, id)
+		if err != nil {
+			return err
+		}
+		ws.Name = name
+		return nil
+	})
+	return err
+}
+
+// DeleteWorkspace removes the workspace id and its session.
+// The last workspace of a directory cannot be deleted; deleting the active one
+// activates the most recently used of the others.
+func (a *App) DeleteWorkspace(id string) error {
+	wasActive := false
+	sessionName := ""
+	_, err := a.updateWorkspaces(a.initialDir, func(idx *workspaceIndex, dirHash string) error {
+		if idx.find(id) == nil {
+			return fmt.Errorf("workspace not found: %s", id)
+		}
+		if len(idx.Workspaces) == 1 {
+			return fmt.Errorf("cannot delete the only workspace")
+		}
+		sessionName = workspaceSessionName(dirHash, id)
+
+		kept := idx.Workspaces[:0]
+		for _, ws := range idx.Workspaces {
+			if ws.ID != id {
+				kept = append(kept, ws)
+			}
+		}
+		idx.Workspaces = kept
+
+		if idx.Active == id {
+			wasActive = true
+			idx.Active = idx.recent()[0].ID
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := a.state.Remove(sessionName); err != nil {
+		return fmt.Errorf("failed to remove session: %v", err)
+	}
+
+	// The frontend loads the session of the newly active workspace
+	if wasActive {
+		a.restoreWorkspaceOptions()
+	}
+	return nil
+}
+
+// SwitchWorkspace makes the workspace id active and returns its session, or nil if it has none.
+// The frontend saves the session of the previous workspace first.
+func (a *App) SwitchWorkspace(id string) (*DirectorySessionCache, error) {
+	_, err := a.updateWorkspaces(a.initialDir, func(idx *workspaceIndex, _ string) error {
+		ws := idx.find(id)
+		if ws == nil {
+			return fmt.Errorf("workspace not found: %s", id)
+		}
+		ws.LastUsed = time.Now()
+		idx.Active = id
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := a.LoadDirectorySessionCache(a.initialDir)
+	if err != nil {
+		return nil, err
+	}
+	a.applySessionOptions(session)
+	return session, nil
+}
+
+// restoreWorkspaceOptions makes the output options of the active workspace of the working directory active
+func (a *App) restoreWorkspaceOptions() {
+	if a.initialDir == "" {
+		return
+	}
+	session, err := a.LoadDirectorySessionCache(a.initialDir)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		return
+	}
+	a.applySessionOptions(session)
+}
+
+// applySessionOptions replaces the active page selections, options and output path with those of session
+func (a *App) applySessionOptions(session *DirectorySessionCache) {
+	if session == nil || (len(session.PageSelections) == 0 && session.PostProcess == nil && session.OutputPath == "") {
+		a.setRecipe("", nil)
+		return
+	}
+
+	opts := &BundleOptions{
+		PageSelections: session.PageSelections,
+		OutputPath:     session.OutputPath,
+	}
+	if opts.PageSelections == nil {
+		opts.PageSelections = make(map[string]string)
+	}
+	if session.PostProcess != nil {
+		opts.PostProcess = *session.PostProcess
+	}
+	a.setRecipe("", opts)
+}
+
+// workspacesChanged publishes the workspace list and updates the menu
+func (a *App) workspacesChanged(idx workspaceIndex) {
+	a.emit(eventWorkspaceChanged, WorkspaceChangedEvent{
+		Directory:  idx.DirectoryPath,
+		Active:     idx.Active,
+		Workspaces: a.localizeWorkspaces(idx.recent()),
+	})
+	a.refreshWorkspaceMenu()
+}
+
+// setWorkspaceMenu fills submenu with the recent workspaces and keeps it up to date
+func (a *App) setWorkspaceMenu(submenu *menu.Menu) {
+	a.workspaceMenu = submenu
+	a.refreshWorkspaceMenu()
+}
+
+// refreshWorkspaceMenu lists the recent workspaces of the working directory in the menu
+func (a *App) refreshWorkspaceMenu() {
+	if a.workspaceMenu == nil {
+		return
+	}
+
+	a.workspaceMenu.Items = nil
+	if idx, err := a.loadWorkspaces(a.initialDir); err == nil {
+		workspaces := a.localizeWorkspaces(idx.recent())
+		if len(workspaces) > maxRecentWorkspaces {
+			workspaces = workspaces[:maxRecentWorkspaces]
+		}
+		for _, ws := range workspaces {
+			id := ws.ID
+			a.workspaceMenu.AddRadio(ws.Name, ws.Active, nil, func(_ *menu.CallbackData) {
+				// The selection lives in the frontend, so let it save the session and switch
+				a.emit(eventWorkspaceSwitchRequest, id)
+			})
+		}
+		a.workspaceMenu.AddSeparator()
+	}
+	a.workspaceMenu.AddText(a.text("menu.newWorkspace"), nil, func(_ *menu.CallbackData) {
+		a.emit(eventWorkspaceCreateRequest, nil)
+	})
+
+	if a.ctx != nil {
+		runtime.MenuUpdateApplicationMenu(a.ctx)
+	}
+}
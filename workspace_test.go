@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLocalizeWorkspaces(t *testing.T) {
+	app := &App{settings: defaultSettings()}
+	app.settings.Language = "en"
+
+	workspaces := []Workspace{
+		{ID: defaultWorkspaceID},                                   // Created by this version
+		{ID: defaultWorkspaceID, Name: legacyDefaultWorkspaceName}, // Stored by an earlier version
+		{ID: defaultWorkspaceID, Name: "Mine"},                     // Renamed by the user
+		{ID: "a1b2c3d4", Name: legacyDefaultWorkspaceName},         // Another workspace keeps its name
+	}
+	var names []string
+	for _, ws := range app.localizeWorkspaces(workspaces) {
+		names = append(names, ws.Name)
+	}
+	if want := []string{"Default", "Default", "Mine", legacyDefaultWorkspaceName}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("names %q, want %q", names, want)
+	}
+}
+
+func TestCheckNameReservesDefaultName(t *testing.T) {
+	idx := newWorkspaceIndex("/work")
+	idx.Workspaces = append(idx.Workspaces, Workspace{ID: "a1b2c3d4", Name: "Draft"})
+
+	tests := []struct {
+		name    string
+		id      string
+		wantErr bool
+	}{
+		{name: "標準", wantErr: true},
+		{name: "default", wantErr: true},
+		{name: "draft", wantErr: true},
+		{name: "Draft", id: "a1b2c3d4"},
+		{name: "Default", id: defaultWorkspaceID},
+		{name: "Final"},
+		{name: "  ", wantErr: true},
+	}
+	for _, tt := range tests {
+		_, err := idx.checkName(tt.name, tt.id)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkName(%q, %q) error %v, want error %v", tt.name, tt.id, err, tt.wantErr)
+		}
+	}
+
+	// Once renamed, the default workspace no longer reserves its default name
+	idx.find(defaultWorkspaceID).Name = "Mine"
+	if _, err := idx.checkName("Default", ""); err != nil {
+		t.Fatalf("checkName(Default) after rename: %v", err)
+	}
+}